@@ -8,8 +8,8 @@ var (
 )
 
 var (
-	// EnvFile is the path to a file containing environment variables to be used in the tests
-	EnvFile string
+	// EnvFiles are the paths to files containing environment variables to be used in the tests
+	EnvFiles []string
 	// SearchPath is the path to search for test files
 	SearchPath string
 	// ConcurrentSuites is the number of concurrent tests suites to run
@@ -37,7 +37,7 @@ func NewRootCmd() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "v", false, "Enable verbose output")
-	cmd.PersistentFlags().StringVarP(&EnvFile, "envfile", "e", ".env", "Environment file to load environment variables from")
+	cmd.PersistentFlags().StringArrayVarP(&EnvFiles, "envfile", "e", []string{".env"}, "Environment file to load environment variables from (repeatable, later files override earlier ones)")
 	cmd.PersistentFlags().StringVarP(&SearchPath, "searchpath", "p", defaultSearchPath, "Path to search for test files")
 	cmd.PersistentFlags().StringSliceVarP(&FileExtensions, "include", "i", defaultTestFileExtensions, "Include tests with the specified extensions")
 	cmd.PersistentFlags().IntVarP(&ConcurrentSuites, "concurrency", "c", 2, "Number of concurrent tests defintions to execute")
@@ -1,7 +1,14 @@
 package httpprobe
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/mrfoh/httpprobe/internal/logging"
 	"github.com/mrfoh/httpprobe/internal/runner"
@@ -11,6 +18,28 @@ import (
 	"go.uber.org/zap"
 )
 
+// exitInterrupted is the exit code of a run stopped by SIGINT or SIGTERM
+const exitInterrupted = 130
+
+// errInterrupted is the cause of a run cancelled by a signal
+var errInterrupted = errors.New("interrupted")
+
+// handleSignals cancels the run on the first SIGINT or SIGTERM and exits immediately on
+// the second, for when teardown hooks hang
+func handleSignals(cancel context.CancelCauseFunc, logger logging.Logger) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		logger.Warn("Stopping the run, press Ctrl-C again to exit immediately", zap.String("signal", sig.String()))
+		cancel(fmt.Errorf("%w: received %s signal", errInterrupted, sig))
+
+		<-signals
+		os.Exit(exitInterrupted)
+	}()
+}
+
 func NewRunCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run",
@@ -23,14 +52,58 @@ func NewRunCmd() *cobra.Command {
 			verbose, _ := cmd.Flags().GetBool("verbose")
 			output, _ := cmd.Flags().GetString("output")
 			outputfile, _ := cmd.Flags().GetString("outputfile")
-			envFile, _ := cmd.Flags().GetString("envfile")
+			envFiles, _ := cmd.Flags().GetStringArray("envfile")
+			keepEnv, _ := cmd.Flags().GetBool("keep-env")
+			strict, _ := cmd.Flags().GetBool("strict")
+			environment, _ := cmd.Flags().GetString("env")
+			varFlags, _ := cmd.Flags().GetStringArray("var")
+			varFiles, _ := cmd.Flags().GetStringArray("var-file")
+			allowCmdSecrets, _ := cmd.Flags().GetBool("allow-cmd-secrets")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			maxDuration, _ := cmd.Flags().GetDuration("max-duration")
+			proxy, _ := cmd.Flags().GetString("proxy")
+			noProxy, _ := cmd.Flags().GetStringSlice("no-proxy")
+			resolveFlags, _ := cmd.Flags().GetStringArray("resolve")
+			httpVersion, _ := cmd.Flags().GetString("http-version")
+			userAgent, _ := cmd.Flags().GetString("user-agent")
 
-			// Load environment variables from file
-			if err := tests.LoadEnvFile(envFile); err != nil {
+			// Seed generated data so a run can be reproduced. Without --seed a new seed is
+			// picked and printed in the report so the run can be replayed
+			seed := time.Now().UnixNano()
+			if cmd.Flags().Changed("seed") {
+				seed, _ = cmd.Flags().GetInt64("seed")
+			}
+			tests.SeedRandom(seed)
+
+			// Load environment variables from files, layered in order. The values are kept in
+			// the run's environment rather than the process environment
+			env := tests.NewEnvironment()
+			if err := env.LoadFiles(envFiles, !keepEnv); err != nil {
 				cmd.PrintErrf("Error loading environment variables: %v\n", err)
 				return
 			}
 
+			// Collect variable overrides; --var takes precedence over --var-file
+			overrides := make(map[string]tests.Variable)
+			for _, varFile := range varFiles {
+				fileVars, err := tests.LoadVariablesFile(varFile)
+				if err != nil {
+					cmd.PrintErrln(err)
+					return
+				}
+				for k, v := range fileVars {
+					overrides[k] = v
+				}
+			}
+			for _, varFlag := range varFlags {
+				name, variable, err := tests.ParseVariableOverride(varFlag)
+				if err != nil {
+					cmd.PrintErrln(err)
+					return
+				}
+				overrides[name] = variable
+			}
+
 			var loggingLevel string
 
 			if verbose {
@@ -47,12 +120,71 @@ func NewRunCmd() *cobra.Command {
 				return
 			}
 
-			if envFile != "" {
-				logger.Debug("Loaded environment variables from file", zap.String("file", envFile))
+			// Secret values are redacted from everything logged during the run
+			secrets := tests.NewSecretStore()
+			secrets.AddVariables(overrides)
+			logger = logging.NewRedactingLogger(logger, secrets.Redact)
+
+			if len(envFiles) > 0 {
+				logger.Debug("Loaded environment variables from files", zap.Strings("files", envFiles))
+			}
+
+			// Load the selected environment profile from the current directory or the search path
+			profileDirs := []string{"."}
+			if info, err := os.Stat(testFilesSearchPath); err == nil && !info.IsDir() {
+				profileDirs = append(profileDirs, filepath.Dir(testFilesSearchPath))
+			} else {
+				profileDirs = append(profileDirs, testFilesSearchPath)
+			}
+
+			// ${cmd:...} references run local commands, so they must be enabled explicitly
+			secretProviders := tests.DefaultSecretProviders()
+			if allowCmdSecrets {
+				secretProviders["cmd"] = tests.CommandSecretProvider{}
+			}
+
+			profileInterpolator := &tests.Interpolator{Providers: secretProviders, Secrets: secrets, Env: env}
+			profile, err := profileInterpolator.LoadEnvironmentProfile(environment, profileDirs)
+			if err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+			if profile != nil {
+				logger.Debug("Loaded environment profile", zap.String("name", profile.Name), zap.String("file", profile.Path))
+			}
+
+			// Requests identify httpprobe and its version unless --user-agent is set
+			if !cmd.Flags().Changed("user-agent") {
+				userAgent = fmt.Sprintf("%s/%s", easyreq.DefaultUserAgent, VERSION)
 			}
 
 			httpClientOptions := easyreq.NewOptions().
-				WithLogger(logger)
+				WithLogger(logger).
+				WithTimeout(int(timeout.Milliseconds())).
+				WithUserAgent(userAgent)
+
+			// Without --proxy, proxies are taken from the HTTP_PROXY and HTTPS_PROXY environment variables
+			if cmd.Flags().Changed("proxy") {
+				httpClientOptions.WithProxy(&easyreq.ProxyOptions{URL: proxy, NoProxy: noProxy})
+			} else if len(noProxy) > 0 {
+				cmd.PrintErrln("--no-proxy requires --proxy, use the NO_PROXY environment variable with environment proxies")
+				return
+			}
+
+			if err := easyreq.ValidateHTTPVersion(httpVersion); err != nil {
+				cmd.PrintErrln(err)
+				return
+			}
+			httpClientOptions.WithHTTPVersion(httpVersion)
+
+			if len(resolveFlags) > 0 {
+				resolve, err := easyreq.ParseResolve(resolveFlags)
+				if err != nil {
+					cmd.PrintErrln(err)
+					return
+				}
+				httpClientOptions.WithResolve(resolve)
+			}
 
 			httpClient := easyreq.New(httpClientOptions)
 
@@ -65,7 +197,15 @@ func NewRunCmd() *cobra.Command {
 				SetParser(parser).
 				SetConcurrency(concurrency).
 				SetHttpClient(httpClient).
-				SetResultWriter(writer)
+				SetHttpClientOptions(httpClientOptions).
+				SetResultWriter(writer).
+				SetStrictVariables(strict).
+				SetSeed(seed).
+				SetEnvironmentProfile(profile).
+				SetVariableOverrides(overrides).
+				SetSecretStore(secrets).
+				SetSecretProviders(secretProviders).
+				SetEnvironment(env)
 
 			testrunner := runner.NewRunner(runnerOptions)
 
@@ -78,7 +218,24 @@ func NewRunCmd() *cobra.Command {
 				return
 			}
 
-			results, err := testrunner.Execute(definitions)
+			// Stop the run on SIGINT or SIGTERM: in-flight requests are cancelled, remaining
+			// cases are reported as cancelled and the partial results are still written
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, interrupt := context.WithCancelCause(ctx)
+			defer interrupt(nil)
+			handleSignals(interrupt, logger)
+
+			// Bound the whole run with --max-duration; cases that have not started by then are not run
+			if maxDuration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeoutCause(ctx, maxDuration, fmt.Errorf("run exceeded max duration of %s", maxDuration))
+				defer cancel()
+			}
+
+			results, err := testrunner.Execute(ctx, definitions)
 			if err != nil {
 				cmd.PrintErrln(err)
 				return
@@ -86,6 +243,10 @@ func NewRunCmd() *cobra.Command {
 
 			testrunner.Write(results)
 
+			if errors.Is(context.Cause(ctx), errInterrupted) {
+				os.Exit(exitInterrupted)
+			}
+
 			for _, defResult := range results {
 				for _, suiteResult := range defResult.Suites {
 					for _, caseResult := range suiteResult.Cases {
@@ -99,6 +260,20 @@ func NewRunCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringP("outputfile", "f", "", "Output file to write results to")
+	cmd.Flags().Bool("strict", true, "Fail test cases that reference undefined variables")
+	cmd.Flags().String("env", "", "Environment profile to use, loaded from httpprobe.env.<env>.yaml")
+	cmd.Flags().StringArray("var", nil, "Set a variable for this run as key=value or key:type=value (repeatable)")
+	cmd.Flags().StringArray("var-file", nil, "Load variables for this run from a YAML or JSON file (repeatable)")
+	cmd.Flags().Bool("keep-env", false, "Do not let environment files override environment variables that are already set")
+	cmd.Flags().Bool("allow-cmd-secrets", false, "Allow ${cmd:...} variables to run local commands to read secrets")
+	cmd.Flags().Duration("timeout", 10*time.Second, "Default timeout for each request, overridden by timeouts in test definitions")
+	cmd.Flags().Duration("max-duration", 0, "Maximum duration of the whole run, e.g. 5m. Test cases not started in time are not run")
+	cmd.Flags().String("proxy", "", "Proxy for all requests, e.g. http://localhost:8080 or socks5://localhost:1080. An empty value connects directly")
+	cmd.Flags().StringSlice("no-proxy", nil, "Hosts, domains or CIDR ranges to connect to directly instead of through --proxy")
+	cmd.Flags().StringArray("resolve", nil, "Connect to an IP address instead of looking a host up, as host:port:address (repeatable)")
+	cmd.Flags().String("http-version", "", "HTTP version for all requests: 1.1, 2 or h2c. By default HTTP/2 is used when negotiated over TLS")
+	cmd.Flags().String("user-agent", "", "User-Agent of all requests, unless set by a test case. Defaults to httpprobe/<version>, an empty value sends none")
+	cmd.Flags().Int64("seed", 0, "Seed for random and fake data generators, to reproduce generated values")
 
 	return cmd
 }
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactingLogger wraps a Logger and passes every message and field through a redact
+// function before logging it, so secret values never reach the log output
+type RedactingLogger struct {
+	logger Logger
+	redact func(string) string
+}
+
+// NewRedactingLogger creates a Logger that redacts messages and fields with redact before
+// passing them to logger
+func NewRedactingLogger(logger Logger, redact func(string) string) Logger {
+	return &RedactingLogger{
+		logger: logger,
+		redact: redact,
+	}
+}
+
+func (l *RedactingLogger) Debug(msg string, fields ...zapcore.Field) {
+	l.logger.Debug(l.redact(msg), l.redactFields(fields)...)
+}
+
+func (l *RedactingLogger) Info(msg string, fields ...zapcore.Field) {
+	l.logger.Info(l.redact(msg), l.redactFields(fields)...)
+}
+
+func (l *RedactingLogger) Warn(msg string, fields ...zapcore.Field) {
+	l.logger.Warn(l.redact(msg), l.redactFields(fields)...)
+}
+
+func (l *RedactingLogger) Error(msg string, fields ...zapcore.Field) {
+	l.logger.Error(l.redact(msg), l.redactFields(fields)...)
+}
+
+func (l *RedactingLogger) Fatal(msg string, fields ...zapcore.Field) {
+	l.logger.Fatal(l.redact(msg), l.redactFields(fields)...)
+}
+
+// redactFields returns a copy of fields with string, error and structured values redacted.
+// Structured values are logged as their JSON encoding.
+func (l *RedactingLogger) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			redacted[i] = zap.String(field.Key, l.redact(field.String))
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				redacted[i] = zap.String(field.Key, l.redact(err.Error()))
+			} else {
+				redacted[i] = field
+			}
+		case zapcore.StringerType:
+			if s, ok := field.Interface.(fmt.Stringer); ok {
+				redacted[i] = zap.String(field.Key, l.redact(s.String()))
+			} else {
+				redacted[i] = field
+			}
+		case zapcore.ReflectType, zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+			data, err := json.Marshal(field.Interface)
+			if err != nil {
+				redacted[i] = zap.String(field.Key, l.redact(fmt.Sprintf("%v", field.Interface)))
+			} else {
+				redacted[i] = zap.String(field.Key, l.redact(string(data)))
+			}
+		default:
+			redacted[i] = field
+		}
+	}
+	return redacted
+}
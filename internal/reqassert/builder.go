@@ -17,6 +17,10 @@ func NewBuilder() *Builder {
 	registry.Register("status", &StatusAssertionFactory{})
 	registry.Register("headers", &HeaderAssertionFactory{})
 	registry.Register("body", &BodyAssertionFactory{})
+	registry.Register("tls", &TLSAssertionFactory{})
+	registry.Register("cookies", &CookieAssertionFactory{})
+	registry.Register("redirects", &RedirectAssertionFactory{})
+	registry.Register("protocol", &ProtocolAssertionFactory{})
 	
 	return &Builder{
 		registry: registry,
@@ -41,6 +45,15 @@ func (b *Builder) BuildAssertions(assertionData map[string]interface{}) ([]Asser
 		assertions = append(assertions, assertion)
 	}
 	
+	// Process protocol assertion
+	if protocol, ok := assertionData["protocol"]; ok {
+		assertion, err := b.registry.Create("protocol", "", protocol)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, assertion)
+	}
+	
 	// Process header assertions
 	if headers, ok := assertionData["headers"].(map[string]interface{}); ok {
 		for headerName, expectedValue := range headers {
@@ -63,6 +76,39 @@ func (b *Builder) BuildAssertions(assertionData map[string]interface{}) ([]Asser
 		}
 	}
 	
+	// Process TLS assertions
+	if tlsData, ok := assertionData["tls"].(map[string]interface{}); ok {
+		for field, expectedValue := range tlsData {
+			assertion, err := b.registry.Create("tls", field, expectedValue)
+			if err != nil {
+				return nil, err
+			}
+			assertions = append(assertions, assertion)
+		}
+	}
+	
+	// Process cookie assertions
+	if cookies, ok := assertionData["cookies"].(map[string]interface{}); ok {
+		for name, expectedValue := range cookies {
+			assertion, err := b.registry.Create("cookies", name, expectedValue)
+			if err != nil {
+				return nil, err
+			}
+			assertions = append(assertions, assertion)
+		}
+	}
+	
+	// Process redirect assertions
+	if redirects, ok := assertionData["redirects"].(map[string]interface{}); ok {
+		for field, expectedValue := range redirects {
+			assertion, err := b.registry.Create("redirects", field, expectedValue)
+			if err != nil {
+				return nil, err
+			}
+			assertions = append(assertions, assertion)
+		}
+	}
+	
 	return assertions, nil
 }
 
@@ -0,0 +1,227 @@
+package reqassert
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// durationComparisonPattern matches an optional comparison operator followed by a duration
+var durationComparisonPattern = regexp.MustCompile(`^\s*(>=|<=|>|<|==|=)?\s*(\S+)\s*$`)
+
+// CookieAssertion validates a cookie set by the response
+type CookieAssertion struct {
+	// Name of the cookie
+	Name string
+	// Exists is whether the response is expected to set the cookie
+	Exists bool
+	// Value is the expected value, if set
+	Value *string
+	// Secure is the expected Secure attribute, if set
+	Secure *bool
+	// HttpOnly is the expected HttpOnly attribute, if set
+	HttpOnly *bool
+	// SameSite is the expected SameSite attribute (Lax, Strict or None), if set
+	SameSite string
+	// Domain is the expected Domain attribute, if set
+	Domain string
+	// Path is the expected Path attribute, if set
+	Path string
+	// Session is whether the cookie is expected to be a session cookie without an expiry, if set
+	Session *bool
+	// ExpiresIn compares the time until the cookie expires, e.g. ">= 1h"
+	ExpiresIn string
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// Validate checks the cookie against the expected attributes
+func (a *CookieAssertion) Validate(ctx *AssertionContext) error {
+	var cookie *http.Cookie
+	for _, c := range ctx.Cookies {
+		if c.Name == a.Name {
+			cookie = c
+		}
+	}
+
+	if cookie == nil {
+		if a.Exists {
+			return fmt.Errorf("cookie '%s' not set by response", a.Name)
+		}
+		return nil
+	}
+	if !a.Exists {
+		return fmt.Errorf("expected cookie '%s' not to be set, got '%s'", a.Name, cookie.Value)
+	}
+
+	if a.Value != nil && cookie.Value != *a.Value {
+		return fmt.Errorf("expected cookie '%s' to be '%s', got '%s'", a.Name, *a.Value, cookie.Value)
+	}
+	if a.Secure != nil && cookie.Secure != *a.Secure {
+		return fmt.Errorf("expected cookie '%s' secure to be %t", a.Name, *a.Secure)
+	}
+	if a.HttpOnly != nil && cookie.HttpOnly != *a.HttpOnly {
+		return fmt.Errorf("expected cookie '%s' http_only to be %t", a.Name, *a.HttpOnly)
+	}
+	if a.SameSite != "" && !strings.EqualFold(sameSiteName(cookie.SameSite), a.SameSite) {
+		return fmt.Errorf("expected cookie '%s' same_site to be %s, got %s", a.Name, a.SameSite, sameSiteName(cookie.SameSite))
+	}
+	if a.Domain != "" && !strings.EqualFold(strings.TrimPrefix(cookie.Domain, "."), strings.TrimPrefix(a.Domain, ".")) {
+		return fmt.Errorf("expected cookie '%s' domain to be %s, got %s", a.Name, a.Domain, cookie.Domain)
+	}
+	if a.Path != "" && cookie.Path != a.Path {
+		return fmt.Errorf("expected cookie '%s' path to be %s, got %s", a.Name, a.Path, cookie.Path)
+	}
+
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	expiresIn, persistent := cookieLifetime(cookie, now())
+
+	if a.Session != nil && persistent == *a.Session {
+		if *a.Session {
+			return fmt.Errorf("expected cookie '%s' to be a session cookie, expires in %s", a.Name, expiresIn)
+		}
+		return fmt.Errorf("expected cookie '%s' to have an expiry, it is a session cookie", a.Name)
+	}
+
+	if a.ExpiresIn != "" {
+		if !persistent {
+			return fmt.Errorf("expected cookie '%s' to expire in %s, it is a session cookie", a.Name, a.ExpiresIn)
+		}
+		operator, expected, err := parseDurationComparison(a.ExpiresIn)
+		if err != nil {
+			return err
+		}
+		if !compareWithOperator(expiresIn.Seconds(), operator, expected.Seconds()) {
+			return fmt.Errorf("expected cookie '%s' to expire in %s %s, expires in %s", a.Name, operator, expected, expiresIn)
+		}
+	}
+
+	return nil
+}
+
+// cookieLifetime returns the time until the cookie expires and whether it has an expiry.
+// Max-Age takes precedence over Expires.
+func cookieLifetime(cookie *http.Cookie, now time.Time) (time.Duration, bool) {
+	switch {
+	case cookie.MaxAge > 0:
+		return time.Duration(cookie.MaxAge) * time.Second, true
+	case cookie.MaxAge < 0:
+		return 0, true
+	case !cookie.Expires.IsZero():
+		return cookie.Expires.Sub(now).Round(time.Second), true
+	}
+	return 0, false
+}
+
+// sameSiteName returns the attribute name of a SameSite mode
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+// parseDurationComparison splits a value such as ">= 1h" into its operator and duration.
+// Without an operator the duration is a minimum.
+func parseDurationComparison(value string) (string, time.Duration, error) {
+	matches := durationComparisonPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return "", 0, fmt.Errorf("invalid expires_in value '%s', expected a duration such as 1h", value)
+	}
+
+	duration, err := time.ParseDuration(matches[2])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid expires_in value '%s', expected a duration such as 1h", value)
+	}
+
+	operator := matches[1]
+	if operator == "" {
+		operator = ">="
+	}
+	return operator, duration, nil
+}
+
+// CookieAssertionFactory creates cookie assertions
+type CookieAssertionFactory struct{}
+
+// Create returns a new CookieAssertion. The expected value is "exists", "absent", the
+// expected cookie value, or a map of expected attributes.
+func (f *CookieAssertionFactory) Create(key string, expected interface{}) (Assertion, error) {
+	assertion := &CookieAssertion{Name: key, Exists: true}
+
+	switch v := expected.(type) {
+	case string:
+		switch v {
+		case "exists":
+		case "absent":
+			assertion.Exists = false
+		default:
+			value := v
+			assertion.Value = &value
+		}
+	case bool:
+		assertion.Exists = v
+	case map[string]interface{}:
+		for attribute, attributeValue := range v {
+			if err := assertion.setAttribute(attribute, attributeValue); err != nil {
+				return nil, fmt.Errorf("cookie '%s': %v", key, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cookie '%s' assertion must be a string or a map, got %T", key, expected)
+	}
+
+	return assertion, nil
+}
+
+// setAttribute sets an expected attribute from the map form of a cookie assertion
+func (a *CookieAssertion) setAttribute(attribute string, value interface{}) error {
+	switch attribute {
+	case "exists", "secure", "http_only", "session":
+		flag, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("%s must be true or false, got %T", attribute, value)
+		}
+		switch attribute {
+		case "exists":
+			a.Exists = flag
+		case "secure":
+			a.Secure = &flag
+		case "http_only":
+			a.HttpOnly = &flag
+		case "session":
+			a.Session = &flag
+		}
+		return nil
+	}
+
+	text := fmt.Sprintf("%v", value)
+	switch attribute {
+	case "value":
+		a.Value = &text
+	case "same_site":
+		a.SameSite = text
+	case "domain":
+		a.Domain = text
+	case "path":
+		a.Path = text
+	case "expires_in":
+		if _, _, err := parseDurationComparison(text); err != nil {
+			return err
+		}
+		a.ExpiresIn = text
+	default:
+		return fmt.Errorf("unknown cookie attribute: %s", attribute)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package reqassert
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCookieAssertionValidate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cookies := []*http.Cookie{
+		{Name: "session", Value: "abc123", Path: "/", Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode},
+		{Name: "remember", Value: "yes", Domain: ".example.com", MaxAge: 7200},
+		{Name: "theme", Value: "dark", Expires: now.Add(30 * time.Minute)},
+	}
+
+	tests := []struct {
+		name        string
+		cookie      string
+		expected    interface{}
+		shouldError bool
+	}{
+		{name: "exists - pass", cookie: "session", expected: "exists"},
+		{name: "exists - fail", cookie: "csrf", expected: "exists", shouldError: true},
+		{name: "absent - pass", cookie: "csrf", expected: "absent"},
+		{name: "absent - fail", cookie: "session", expected: "absent", shouldError: true},
+		{name: "value - pass", cookie: "theme", expected: "dark"},
+		{name: "value - fail", cookie: "theme", expected: "light", shouldError: true},
+		{
+			name:     "flags - pass",
+			cookie:   "session",
+			expected: map[string]interface{}{"secure": true, "http_only": true, "same_site": "lax", "path": "/", "session": true},
+		},
+		{
+			name:        "flags - fail",
+			cookie:      "remember",
+			expected:    map[string]interface{}{"secure": true},
+			shouldError: true,
+		},
+		{
+			name:     "max age expiry - pass",
+			cookie:   "remember",
+			expected: map[string]interface{}{"expires_in": ">= 1h", "domain": "example.com"},
+		},
+		{
+			name:        "expires expiry - fail",
+			cookie:      "theme",
+			expected:    map[string]interface{}{"expires_in": "1h"},
+			shouldError: true,
+		},
+		{
+			name:        "expiry of session cookie - fail",
+			cookie:      "session",
+			expected:    map[string]interface{}{"expires_in": "< 1h"},
+			shouldError: true,
+		},
+	}
+
+	factory := &CookieAssertionFactory{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion, err := factory.Create(tt.cookie, tt.expected)
+			if err != nil {
+				t.Fatalf("Failed to create assertion: %v", err)
+			}
+			assertion.(*CookieAssertion).now = func() time.Time { return now }
+
+			err = assertion.Validate(&AssertionContext{Cookies: cookies})
+			if (err != nil) != tt.shouldError {
+				t.Errorf("Expected error: %v, got error: %v - %v", tt.shouldError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestCookieAssertionFactoryCreate(t *testing.T) {
+	factory := &CookieAssertionFactory{}
+
+	invalid := []interface{}{
+		map[string]interface{}{"secure": "yes"},
+		map[string]interface{}{"expires_in": "tomorrow"},
+		map[string]interface{}{"color": "blue"},
+		42,
+	}
+	for _, expected := range invalid {
+		if _, err := factory.Create("session", expected); err == nil {
+			t.Errorf("Expected an error for %v", expected)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package reqassert
 
-import "github.com/pkg/errors"
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
 
 // Assertion defines the interface for validating HTTP responses
 type Assertion interface {
@@ -14,6 +18,17 @@ type AssertionContext struct {
 	Headers    map[string]string
 	Body       []byte
 	BodyMap    map[string]interface{}
+	// TLS describes the TLS connection, or is nil for plain HTTP
+	TLS *TLSState
+	// Cookies are the cookies set by the redirects that were followed and the response, in
+	// order
+	Cookies []*http.Cookie
+	// URL is the final URL of the request, after following redirects
+	URL string
+	// Redirects is the chain of redirects that were followed, in order
+	Redirects []Redirect
+	// Protocol is the protocol of the response, e.g. HTTP/1.1 or HTTP/2.0
+	Protocol string
 }
 
 // AssertionFactory creates assertions from data
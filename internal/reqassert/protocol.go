@@ -0,0 +1,49 @@
+package reqassert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProtocolAssertion validates the HTTP protocol of the response
+type ProtocolAssertion struct {
+	// Expected is the expected protocol, e.g. "2" or "HTTP/1.1"
+	Expected string
+}
+
+// Validate checks if the protocol of the response matches the expected protocol
+func (a *ProtocolAssertion) Validate(ctx *AssertionContext) error {
+	if normalizeProtocol(ctx.Protocol) != normalizeProtocol(a.Expected) {
+		return fmt.Errorf("expected protocol %s, got %s", a.Expected, ctx.Protocol)
+	}
+	return nil
+}
+
+// normalizeProtocol returns the HTTP version of a protocol, so "HTTP/2.0", "HTTP/2", "h2"
+// and "h2c" all compare as "2"
+func normalizeProtocol(protocol string) string {
+	version := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(protocol)), "HTTP/")
+	switch version {
+	case "2", "2.0", "H2", "H2C":
+		return "2"
+	case "3", "3.0", "H3":
+		return "3"
+	}
+	return version
+}
+
+// ProtocolAssertionFactory creates protocol assertions
+type ProtocolAssertionFactory struct{}
+
+// Create returns a new ProtocolAssertion
+func (f *ProtocolAssertionFactory) Create(key string, expected interface{}) (Assertion, error) {
+	switch v := expected.(type) {
+	case string:
+		return &ProtocolAssertion{Expected: v}, nil
+	case int, float64:
+		// YAML parses protocol: 2 and protocol: 1.1 as numbers
+		return &ProtocolAssertion{Expected: fmt.Sprintf("%v", v)}, nil
+	default:
+		return nil, fmt.Errorf("protocol must be a version such as 2 or HTTP/1.1, got %T", expected)
+	}
+}
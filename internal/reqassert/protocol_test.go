@@ -0,0 +1,40 @@
+package reqassert
+
+import (
+	"testing"
+)
+
+func TestProtocolAssertionValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		protocol    string
+		expected    interface{}
+		shouldError bool
+	}{
+		{name: "version - pass", protocol: "HTTP/2.0", expected: 2},
+		{name: "http version - pass", protocol: "HTTP/2.0", expected: "HTTP/2"},
+		{name: "h2c - pass", protocol: "HTTP/2.0", expected: "h2c"},
+		{name: "http/1.1 - pass", protocol: "HTTP/1.1", expected: 1.1},
+		{name: "http/1.1 - fail", protocol: "HTTP/1.1", expected: "2", shouldError: true},
+		{name: "http/2 - fail", protocol: "HTTP/2.0", expected: "HTTP/1.1", shouldError: true},
+	}
+
+	factory := &ProtocolAssertionFactory{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion, err := factory.Create("", tt.expected)
+			if err != nil {
+				t.Fatalf("Failed to create assertion: %v", err)
+			}
+
+			err = assertion.Validate(&AssertionContext{Protocol: tt.protocol})
+			if (err != nil) != tt.shouldError {
+				t.Errorf("Expected error: %v, got error: %v - %v", tt.shouldError, err != nil, err)
+			}
+		})
+	}
+
+	if _, err := factory.Create("", []interface{}{"2"}); err == nil {
+		t.Errorf("Expected an error for a list")
+	}
+}
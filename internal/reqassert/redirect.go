@@ -0,0 +1,102 @@
+package reqassert
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Redirect is a hop in the redirect chain of a response
+type Redirect struct {
+	// Status is the status code of the redirect response
+	Status int
+	// URL is the URL that responded with the redirect
+	URL string
+	// Location is the Location header of the redirect
+	Location string
+}
+
+// RedirectAssertion validates the redirects followed to get the response
+type RedirectAssertion struct {
+	// Field is the property being checked: count, final_url or statuses
+	Field string
+	// Expected is the expected value: a number of redirects, optionally with a comparison
+	// operator, a URL, or a list of status codes
+	Expected interface{}
+}
+
+// Validate checks the redirect chain against the expected value
+func (a *RedirectAssertion) Validate(ctx *AssertionContext) error {
+	switch a.Field {
+	case "count":
+		operator, expected, err := parseNumberComparison("redirects count", fmt.Sprintf("%v", a.Expected), "==")
+		if err != nil {
+			return err
+		}
+		if !compareWithOperator(float64(len(ctx.Redirects)), operator, expected) {
+			return fmt.Errorf("expected redirect count %s %v, got %d", operator, expected, len(ctx.Redirects))
+		}
+	case "final_url":
+		expected := fmt.Sprintf("%v", a.Expected)
+		actual := ctx.URL
+		// A path is compared with the path and query of the final URL
+		if parsed, err := url.Parse(ctx.URL); err == nil && len(expected) > 0 && expected[0] == '/' {
+			actual = parsed.RequestURI()
+		}
+		if actual != expected {
+			return fmt.Errorf("expected final URL '%s', got '%s'", expected, actual)
+		}
+	case "statuses":
+		expected, ok := a.Expected.([]int)
+		if !ok {
+			return fmt.Errorf("redirect statuses must be a list of status codes")
+		}
+		actual := make([]int, len(ctx.Redirects))
+		for i, redirect := range ctx.Redirects {
+			actual[i] = redirect.Status
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(expected) {
+			return fmt.Errorf("expected redirect statuses %v, got %v", expected, actual)
+		}
+	}
+
+	return nil
+}
+
+// RedirectAssertionFactory creates redirect assertions
+type RedirectAssertionFactory struct{}
+
+// Create returns a new RedirectAssertion
+func (f *RedirectAssertionFactory) Create(key string, expected interface{}) (Assertion, error) {
+	switch key {
+	case "count":
+		if _, _, err := parseNumberComparison("redirects count", fmt.Sprintf("%v", expected), "=="); err != nil {
+			return nil, err
+		}
+	case "final_url":
+		if _, ok := expected.(string); !ok {
+			return nil, fmt.Errorf("redirects final_url must be a string, got %T", expected)
+		}
+	case "statuses":
+		list, ok := expected.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("redirects statuses must be a list of status codes, got %T", expected)
+		}
+		statuses := make([]int, 0, len(list))
+		for _, item := range list {
+			status, err := strconv.Atoi(fmt.Sprintf("%v", item))
+			if err != nil {
+				return nil, fmt.Errorf("redirects statuses must be status codes, got %v", item)
+			}
+			statuses = append(statuses, status)
+		}
+		expected = statuses
+	default:
+		return nil, fmt.Errorf("unknown redirects assertion: %s", key)
+	}
+
+	return &RedirectAssertion{
+		Field:    key,
+		Expected: expected,
+	}, nil
+}
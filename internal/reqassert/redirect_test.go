@@ -0,0 +1,63 @@
+package reqassert
+
+import (
+	"testing"
+)
+
+func TestRedirectAssertionValidate(t *testing.T) {
+	ctx := &AssertionContext{
+		URL: "https://example.com/new?page=2",
+		Redirects: []Redirect{
+			{Status: 301, URL: "https://example.com/old", Location: "/mid"},
+			{Status: 302, URL: "https://example.com/mid", Location: "/new?page=2"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		field       string
+		expected    interface{}
+		shouldError bool
+	}{
+		{name: "count - pass", field: "count", expected: 2},
+		{name: "count - fail", field: "count", expected: 1, shouldError: true},
+		{name: "count comparison - pass", field: "count", expected: "<= 3"},
+		{name: "count comparison - fail", field: "count", expected: "< 2", shouldError: true},
+		{name: "final url - pass", field: "final_url", expected: "https://example.com/new?page=2"},
+		{name: "final url path - pass", field: "final_url", expected: "/new?page=2"},
+		{name: "final url - fail", field: "final_url", expected: "/new", shouldError: true},
+		{name: "statuses - pass", field: "statuses", expected: []interface{}{301, 302}},
+		{name: "statuses - fail", field: "statuses", expected: []interface{}{302}, shouldError: true},
+	}
+
+	factory := &RedirectAssertionFactory{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion, err := factory.Create(tt.field, tt.expected)
+			if err != nil {
+				t.Fatalf("Failed to create assertion: %v", err)
+			}
+
+			err = assertion.Validate(ctx)
+			if (err != nil) != tt.shouldError {
+				t.Errorf("Expected error: %v, got error: %v - %v", tt.shouldError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestRedirectAssertionFactoryCreate(t *testing.T) {
+	factory := &RedirectAssertionFactory{}
+
+	invalid := map[string]interface{}{
+		"count":     "many",
+		"final_url": 42,
+		"statuses":  []interface{}{"moved"},
+		"hops":      2,
+	}
+	for field, expected := range invalid {
+		if _, err := factory.Create(field, expected); err == nil {
+			t.Errorf("Expected an error for %s: %v", field, expected)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package reqassert
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLSState contains the TLS connection details of a response
+type TLSState struct {
+	// Version is the negotiated TLS version, e.g. "1.3"
+	Version string
+	// Subject is the distinguished name of the server certificate
+	Subject string
+	// Issuer is the distinguished name of the server certificate's issuer
+	Issuer string
+	// DNSNames are the subject alternative names of the server certificate
+	DNSNames []string
+	// NotAfter is when the server certificate expires
+	NotAfter time.Time
+}
+
+// numberComparisonPattern matches an optional comparison operator followed by a number
+var numberComparisonPattern = regexp.MustCompile(`^\s*(>=|<=|>|<|==|=)?\s*(-?\d+(?:\.\d+)?)\s*$`)
+
+// TLSAssertion validates the TLS connection and the server certificate
+type TLSAssertion struct {
+	// Field is the TLS property being checked: expires_in_days, subject, issuer, dns_name or version
+	Field string
+	// Expected is the expected value. For expires_in_days it is a number of days, optionally
+	// prefixed with a comparison operator; without an operator it is the minimum.
+	Expected string
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// Validate checks the TLS property against the expected value
+func (a *TLSAssertion) Validate(ctx *AssertionContext) error {
+	if ctx.TLS == nil {
+		return fmt.Errorf("tls %s: response was not received over TLS", a.Field)
+	}
+
+	switch a.Field {
+	case "expires_in_days":
+		now := time.Now
+		if a.now != nil {
+			now = a.now
+		}
+		days := math.Floor(ctx.TLS.NotAfter.Sub(now()).Hours() / 24)
+
+		operator, expected, err := parseNumberComparison(a.Field, a.Expected, ">=")
+		if err != nil {
+			return err
+		}
+		if !compareWithOperator(days, operator, expected) {
+			return fmt.Errorf("expected certificate to expire in %s %v days, expires in %v days (%s)",
+				operator, expected, days, ctx.TLS.NotAfter.UTC().Format(time.RFC3339))
+		}
+	case "subject":
+		if !strings.Contains(ctx.TLS.Subject, a.Expected) {
+			return fmt.Errorf("expected certificate subject to contain '%s', got '%s'", a.Expected, ctx.TLS.Subject)
+		}
+	case "issuer":
+		if !strings.Contains(ctx.TLS.Issuer, a.Expected) {
+			return fmt.Errorf("expected certificate issuer to contain '%s', got '%s'", a.Expected, ctx.TLS.Issuer)
+		}
+	case "dns_name":
+		for _, name := range ctx.TLS.DNSNames {
+			if strings.EqualFold(name, a.Expected) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected certificate to be valid for '%s', got %v", a.Expected, ctx.TLS.DNSNames)
+	case "version":
+		if ctx.TLS.Version != a.Expected {
+			return fmt.Errorf("expected TLS version %s, got %s", a.Expected, ctx.TLS.Version)
+		}
+	}
+
+	return nil
+}
+
+// parseNumberComparison splits a value such as ">= 30" into its operator and number,
+// using defaultOperator when the value has none. name is the assertion used in errors.
+func parseNumberComparison(name, value, defaultOperator string) (string, float64, error) {
+	matches := numberComparisonPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return "", 0, fmt.Errorf("invalid %s value '%s', expected a number", name, value)
+	}
+
+	number, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid %s value '%s': %v", name, value, err)
+	}
+
+	operator := matches[1]
+	if operator == "" {
+		operator = defaultOperator
+	}
+	return operator, number, nil
+}
+
+// compareWithOperator compares the actual number with the expected number using the operator
+func compareWithOperator(actual float64, operator string, expected float64) bool {
+	switch operator {
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	default:
+		return actual == expected
+	}
+}
+
+// TLSAssertionFactory creates TLS assertions
+type TLSAssertionFactory struct{}
+
+// Create returns a new TLSAssertion
+func (f *TLSAssertionFactory) Create(key string, expected interface{}) (Assertion, error) {
+	var expectedValue string
+	switch v := expected.(type) {
+	case string:
+		expectedValue = v
+	case int:
+		expectedValue = strconv.Itoa(v)
+	case float64:
+		expectedValue = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("tls %s value must be a string or number, got %T", key, expected)
+	}
+
+	switch key {
+	case "expires_in_days":
+		if _, _, err := parseNumberComparison(key, expectedValue, ">="); err != nil {
+			return nil, err
+		}
+	case "subject", "issuer", "dns_name", "version":
+	default:
+		return nil, fmt.Errorf("unknown tls assertion: %s", key)
+	}
+
+	return &TLSAssertion{
+		Field:    key,
+		Expected: expectedValue,
+	}, nil
+}
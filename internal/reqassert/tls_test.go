@@ -0,0 +1,114 @@
+package reqassert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTLSAssertionValidate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	state := &TLSState{
+		Version:  "1.3",
+		Subject:  "CN=api.example.com,O=Example Inc",
+		Issuer:   "CN=Example CA,O=Example Inc",
+		DNSNames: []string{"api.example.com", "www.example.com"},
+		NotAfter: now.Add(45*24*time.Hour + time.Hour),
+	}
+
+	tests := []struct {
+		name        string
+		field       string
+		expected    interface{}
+		state       *TLSState
+		shouldError bool
+	}{
+		{
+			name:     "expires in at least - pass",
+			field:    "expires_in_days",
+			expected: 30,
+			state:    state,
+		},
+		{
+			name:        "expires in at least - fail",
+			field:       "expires_in_days",
+			expected:    60,
+			state:       state,
+			shouldError: true,
+		},
+		{
+			name:     "expires in comparison - pass",
+			field:    "expires_in_days",
+			expected: "< 90",
+			state:    state,
+		},
+		{
+			name:        "expires in comparison - fail",
+			field:       "expires_in_days",
+			expected:    "> 45",
+			state:       state,
+			shouldError: true,
+		},
+		{
+			name:     "subject contains - pass",
+			field:    "subject",
+			expected: "CN=api.example.com",
+			state:    state,
+		},
+		{
+			name:        "issuer contains - fail",
+			field:       "issuer",
+			expected:    "Let's Encrypt",
+			state:       state,
+			shouldError: true,
+		},
+		{
+			name:     "dns name - pass",
+			field:    "dns_name",
+			expected: "WWW.example.com",
+			state:    state,
+		},
+		{
+			name:     "version - pass",
+			field:    "version",
+			expected: "1.3",
+			state:    state,
+		},
+		{
+			name:        "plain http - fail",
+			field:       "subject",
+			expected:    "api.example.com",
+			state:       nil,
+			shouldError: true,
+		},
+	}
+
+	factory := &TLSAssertionFactory{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion, err := factory.Create(tt.field, tt.expected)
+			if err != nil {
+				t.Fatalf("Failed to create assertion: %v", err)
+			}
+			assertion.(*TLSAssertion).now = func() time.Time { return now }
+
+			err = assertion.Validate(&AssertionContext{TLS: tt.state})
+			if (err != nil) != tt.shouldError {
+				t.Errorf("Expected error: %v, got error: %v - %v", tt.shouldError, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestTLSAssertionFactoryCreate(t *testing.T) {
+	factory := &TLSAssertionFactory{}
+
+	if _, err := factory.Create("expires_in_days", "soon"); err == nil {
+		t.Errorf("Expected an error for a non-numeric expires_in_days")
+	}
+	if _, err := factory.Create("serial", "01"); err == nil {
+		t.Errorf("Expected an error for an unknown tls assertion")
+	}
+	if _, err := factory.Create("subject", []string{"a"}); err == nil {
+		t.Errorf("Expected an error for a non-string subject")
+	}
+}
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -9,7 +10,7 @@ import (
 )
 
 // executeHooks runs the specified hook test definitions and returns any exported variables
-func (r *Runner) executeHooks(hookPaths []string, parentVars map[string]tests.Variable) (map[string]tests.Variable, error) {
+func (r *Runner) executeHooks(ctx context.Context, hookPaths []string, parentVars map[string]tests.Variable) (map[string]tests.Variable, error) {
 	// Result map to collect variables from all hooks
 	hookVars := make(map[string]tests.Variable)
 
@@ -52,7 +53,7 @@ func (r *Runner) executeHooks(hookPaths []string, parentVars map[string]tests.Va
 
 		// Execute the hook
 		r.Logger.Debug("Executing hook", zap.String("name", hookDef.Name), zap.String("path", absPath))
-		hookResult, err := r.executeTestDefinition(hookDef)
+		hookResult, err := r.executeTestDefinition(ctx, hookDef)
 		if err != nil {
 			return hookVars, fmt.Errorf("error executing hook %s: %v", hookPath, err)
 		}
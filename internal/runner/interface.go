@@ -1,10 +1,15 @@
 package runner
 
-import "github.com/mrfoh/httpprobe/internal/tests"
+import (
+	"context"
+
+	"github.com/mrfoh/httpprobe/internal/tests"
+)
 
 type TestRunner interface {
 	GetTestDefinitions(params *GetTestDefinitionsParams) ([]*tests.TestDefinition, error)
-	Execute(definition []*tests.TestDefinition) (map[string]tests.TestDefinitionExecResult, error)
+	// Execute runs the test definitions. Test cases that have not started when ctx is done are not run.
+	Execute(ctx context.Context, definition []*tests.TestDefinition) (map[string]tests.TestDefinitionExecResult, error)
 	Write(results map[string]tests.TestDefinitionExecResult)
 }
 
@@ -12,14 +12,33 @@ type TestRunnerOptions struct {
 	// TestDefinitionParser is the parser used to parse test definitions
 	Parser     tests.TestDefinitionParser
 	HttpClient easyreq.HttpClient
+	// HttpClientOptions are the options HttpClient was created with. Test definitions that
+	// configure their own TLS get a client created from a copy of them.
+	HttpClientOptions *easyreq.HttpClientOptions
 	// Number of concurrent test definitions to execute
 	Concurrency int
 	// ResultWriter is the writer to use for writing test results
 	Writer tests.TestResultWriter
+	// StrictVariables fails test cases whose requests reference undefined variables
+	StrictVariables bool
+	// Seed is the seed for random values and generated data in the run
+	Seed int64
+	// Profile is the selected environment profile, if any
+	Profile *tests.EnvironmentProfile
+	// VariableOverrides take precedence over definition and suite variables
+	VariableOverrides map[string]tests.Variable
+	// Secrets collects secret values to redact from logs and results
+	Secrets *tests.SecretStore
+	// SecretProviders resolve ${provider:reference} references in variables
+	SecretProviders tests.SecretProviders
+	// Env holds the environment variables for ${env:...} references in the run
+	Env *tests.Environment
 }
 
 func NewOptions() *TestRunnerOptions {
-	return &TestRunnerOptions{}
+	return &TestRunnerOptions{
+		StrictVariables: true,
+	}
 }
 
 func (o *TestRunnerOptions) Validate() error {
@@ -46,7 +65,47 @@ func (o *TestRunnerOptions) SetHttpClient(httpClient easyreq.HttpClient) *TestRu
 	return o
 }
 
+func (o *TestRunnerOptions) SetHttpClientOptions(httpClientOptions *easyreq.HttpClientOptions) *TestRunnerOptions {
+	o.HttpClientOptions = httpClientOptions
+	return o
+}
+
 func (o *TestRunnerOptions) SetResultWriter(writer tests.TestResultWriter) *TestRunnerOptions {
 	o.Writer = writer
 	return o
 }
+
+func (o *TestRunnerOptions) SetStrictVariables(strict bool) *TestRunnerOptions {
+	o.StrictVariables = strict
+	return o
+}
+
+func (o *TestRunnerOptions) SetSeed(seed int64) *TestRunnerOptions {
+	o.Seed = seed
+	return o
+}
+
+func (o *TestRunnerOptions) SetEnvironmentProfile(profile *tests.EnvironmentProfile) *TestRunnerOptions {
+	o.Profile = profile
+	return o
+}
+
+func (o *TestRunnerOptions) SetVariableOverrides(overrides map[string]tests.Variable) *TestRunnerOptions {
+	o.VariableOverrides = overrides
+	return o
+}
+
+func (o *TestRunnerOptions) SetSecretStore(secrets *tests.SecretStore) *TestRunnerOptions {
+	o.Secrets = secrets
+	return o
+}
+
+func (o *TestRunnerOptions) SetSecretProviders(providers tests.SecretProviders) *TestRunnerOptions {
+	o.SecretProviders = providers
+	return o
+}
+
+func (o *TestRunnerOptions) SetEnvironment(env *tests.Environment) *TestRunnerOptions {
+	o.Env = env
+	return o
+}
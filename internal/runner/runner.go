@@ -1,8 +1,10 @@
 package runner
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,6 +23,26 @@ type Runner struct {
 	HttpClient   easyreq.HttpClient
 	Concurrency  int
 	ResultWriter tests.TestResultWriter
+	// HttpClientOptions are the options HttpClient was created with
+	HttpClientOptions *easyreq.HttpClientOptions
+	// StrictVariables fails test cases whose requests reference undefined variables
+	StrictVariables bool
+	// Seed is the seed for random values and generated data in the run
+	Seed int64
+	// Profile is the selected environment profile, if any
+	Profile *tests.EnvironmentProfile
+	// VariableOverrides take precedence over definition and suite variables
+	VariableOverrides map[string]tests.Variable
+	// Secrets collects secret values to redact from logs and results
+	Secrets *tests.SecretStore
+	// SecretProviders resolve ${provider:reference} references in variables
+	SecretProviders tests.SecretProviders
+	// Env holds the environment variables for ${env:...} references in the run
+	Env *tests.Environment
+	// Tokens caches OAuth2 access tokens for the run, shared by all test definitions
+	Tokens *tests.TokenCache
+	// cancelled is the reason the last execution was stopped early, if it was
+	cancelled error
 	// Map to track processed hooks to prevent infinite recursion
 	processedHooks map[string]bool
 	// Mutex to protect the processed hooks map
@@ -28,13 +50,27 @@ type Runner struct {
 }
 
 func NewRunner(opts *TestRunnerOptions) TestRunner {
+	secrets := opts.Secrets
+	if secrets == nil {
+		secrets = tests.NewSecretStore()
+	}
+
 	return &Runner{
-		Parser:         opts.Parser,
-		Logger:         opts.Logger,
-		HttpClient:     opts.HttpClient,
-		Concurrency:    opts.Concurrency,
-		ResultWriter:   opts.Writer,
-		processedHooks: make(map[string]bool),
+		Parser:            opts.Parser,
+		Logger:            opts.Logger,
+		HttpClient:        opts.HttpClient,
+		HttpClientOptions: opts.HttpClientOptions,
+		Concurrency:       opts.Concurrency,
+		ResultWriter:      opts.Writer,
+		StrictVariables:   opts.StrictVariables,
+		Seed:              opts.Seed,
+		Profile:           opts.Profile,
+		VariableOverrides: opts.VariableOverrides,
+		Secrets:           secrets,
+		SecretProviders:   opts.SecretProviders,
+		Env:               opts.Env,
+		Tokens:            tests.NewTokenCache(),
+		processedHooks:    make(map[string]bool),
 	}
 }
 
@@ -131,11 +167,18 @@ func (r *Runner) GetTestDefinitions(params *GetTestDefinitionsParams) ([]*tests.
 }
 
 // Execute runs the specified test definitions
-func (r *Runner) Execute(definition []*tests.TestDefinition) (map[string]tests.TestDefinitionExecResult, error) {
+func (r *Runner) Execute(ctx context.Context, definition []*tests.TestDefinition) (map[string]tests.TestDefinitionExecResult, error) {
 	result := make(map[string]tests.TestDefinitionExecResult)
 
 	// Reset processed hooks map for a new execution
 	r.processedHooks = make(map[string]bool)
+	r.cancelled = nil
+	defer func() {
+		// Results of a cancelled run are partial; remember why for the report
+		if ctx.Err() != nil {
+			r.cancelled = context.Cause(ctx)
+		}
+	}()
 
 	if r.Concurrency > 1 {
 		// Create a worker pool for executing test definitions concurrently
@@ -151,7 +194,7 @@ func (r *Runner) Execute(definition []*tests.TestDefinition) (map[string]tests.T
 			// Create a local copy of the test definition to avoid closure issues
 			testDef := def
 			pool.Submit(func() {
-				testResult, err := r.executeTestDefinition(testDef)
+				testResult, err := r.executeTestDefinition(ctx, testDef)
 				resultChan <- struct {
 					name   string
 					result tests.TestDefinitionExecResult
@@ -180,7 +223,7 @@ func (r *Runner) Execute(definition []*tests.TestDefinition) (map[string]tests.T
 	} else {
 		// Execute sequentially for single-threaded mode or when hooks require it
 		for _, def := range definition {
-			testResult, err := r.executeTestDefinition(def)
+			testResult, err := r.executeTestDefinition(ctx, def)
 			if err != nil {
 				return nil, err
 			}
@@ -195,7 +238,17 @@ func (r *Runner) Execute(definition []*tests.TestDefinition) (map[string]tests.T
 
 // Write writes the test results
 func (r *Runner) Write(results map[string]tests.TestDefinitionExecResult) {
-	r.ResultWriter.Write(results)
+	info := tests.RunInfo{
+		Seed: r.Seed,
+	}
+	if r.Profile != nil {
+		info.Environment = r.Profile.Name
+	}
+	if r.cancelled != nil {
+		info.Cancelled = r.cancelled.Error()
+	}
+
+	r.ResultWriter.Write(results, info)
 }
 
 // processTestFile reads and parses a single test file
@@ -220,7 +273,7 @@ func (r *Runner) processTestFile(path string) (*tests.TestDefinition, error) {
 	return def, nil
 }
 
-func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDefinitionExecResult, error) {
+func (r *Runner) executeTestDefinition(ctx context.Context, def *tests.TestDefinition) (tests.TestDefinitionExecResult, error) {
 	// Use mutex to protect access to processedHooks map
 	r.hooksMutex.Lock()
 	// Avoid recursive processing of the same definition
@@ -236,24 +289,85 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 	}
 	r.hooksMutex.Unlock()
 
+	// Run a copy of the definition with its own variables, so the overrides, resolved
+	// values and hook variables of this run do not change the parsed definition
+	definition := *def
+	definition.Variables = make(map[string]tests.Variable, len(def.Variables))
+	for k, v := range def.Variables {
+		definition.Variables[k] = v
+	}
+	def = &definition
+
 	result := tests.TestDefinitionExecResult{
 		Path:   def.Path,
 		Suites: make(map[string]tests.TestSuiteResult, len(def.Suites)),
 	}
 
+	// Hooks only run for definitions that started before the run was cancelled. Teardown
+	// hooks of started definitions still run after a cancellation, so they get a context
+	// that is not cancelled with the run.
+	started := ctx.Err() == nil
+	teardownCtx := context.WithoutCancel(ctx)
+
+	// Interpolate relative to the directory of the test definition, with a random source
+	// derived from the run seed so generated values do not depend on execution order
+	interpolator := &tests.Interpolator{
+		BaseDir:   filepath.Dir(def.Path),
+		Random:    tests.NewRandomSource(tests.DefinitionSeed(r.Seed, def)),
+		Providers: r.SecretProviders,
+		Secrets:   r.Secrets,
+		Env:       r.Env,
+	}
+
+	// Variables from the environment profile are layered under the definition variables
+	var profileVars map[string]tests.Variable
+	if r.Profile != nil {
+		profileVars = r.Profile.Variables
+	}
+
+	// Command-line overrides replace definition variables before they are resolved,
+	// so variables that reference them see the overridden values
+	for k, v := range r.VariableOverrides {
+		def.Variables[k] = v
+	}
+
 	// Process environment variables in variable values
-	if err := tests.InterpolateVariableValues(def.Variables); err != nil {
+	if err := interpolator.ResolveVariables(def.Variables, profileVars); err != nil {
 		r.Logger.Error("Error interpolating environment variables in definition variables", zap.Error(err))
 		// Continue execution despite interpolation errors
 	}
 
+	for k, v := range profileVars {
+		if _, exists := def.Variables[k]; !exists {
+			def.Variables[k] = v
+		}
+	}
+
+	// Register secret values before anything is logged
+	r.Secrets.AddVariables(def.Variables)
+
 	r.Logger.Debug(fmt.Sprintf("executing test definition: %s", def.Name))
-	r.Logger.Debug("test definition variables", zap.Any("variables", def.Variables))
+	r.Logger.Debug("test definition variables", zap.Any("variables", r.Secrets.RedactVariables(def.Variables)))
+
+	clientOptions := r.definitionClientOptions(def, interpolator)
+
+	// The definition's defaults apply to its auth and timeout unless it sets them
+	if def.Defaults != nil {
+		if def.Auth == nil {
+			def.Auth = def.Defaults.Auth
+		}
+		if def.Timeout == "" {
+			def.Timeout = def.Defaults.Timeout
+		}
+	}
+
+	// Suites that share the definition's cookie jar use the same jar across the definition
+	var definitionJar http.CookieJar
 
 	// Execute BeforeAll hooks if they exist
-	if len(def.BeforeAll) > 0 {
+	if started && len(def.BeforeAll) > 0 {
 		r.Logger.Debug("Executing BeforeAll hooks", zap.Strings("hooks", def.BeforeAll))
-		hookVars, err := r.executeHooks(def.BeforeAll, def.Variables)
+		hookVars, err := r.executeHooks(ctx, def.BeforeAll, def.Variables)
 		if err != nil {
 			r.Logger.Error("Error executing BeforeAll hooks", zap.Error(err))
 			// We continue execution despite hook errors
@@ -261,13 +375,16 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 
 		// Merge hook variables into definition variables
 		for k, v := range hookVars {
-			if def.Variables == nil {
-				def.Variables = make(map[string]tests.Variable)
-			}
 			def.Variables[k] = v
 		}
 	}
 
+	// Definitions with their own transport configuration get their own client
+	client := r.HttpClient
+	if clientOptions != nil {
+		client = easyreq.New(clientOptions)
+	}
+
 	// Make a copy of the suites to avoid modifying the original
 	for i := range def.Suites {
 		// Create a local copy of the suite to avoid issues with the loop variable
@@ -283,21 +400,34 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 
 		// Then add suite-level variables (to override any definition variables with the same name)
 		if suite.Variables != nil {
-			// First process environment variables in suite-level variable values
-			if err := tests.InterpolateVariableValues(suite.Variables); err != nil {
+			// Suite variables are resolved in a copy, leaving the parsed suite unchanged.
+			// Command-line overrides also take precedence over suite variables.
+			variables := make(map[string]tests.Variable, len(suite.Variables))
+			for k, v := range suite.Variables {
+				if override, exists := r.VariableOverrides[k]; exists {
+					v = override
+				}
+				variables[k] = v
+			}
+			suite.Variables = variables
+
+			// First resolve suite-level variable values against the definition variables
+			if err := interpolator.ResolveVariables(suite.Variables, def.Variables); err != nil {
 				r.Logger.Error("Error interpolating environment variables in suite variables", zap.Error(err))
 				// Continue execution despite interpolation errors
 			}
 
+			r.Secrets.AddVariables(suite.Variables)
+
 			for k, v := range suite.Variables {
 				suiteVars[k] = v
 			}
 		}
 
 		// Execute BeforeEach hooks if they exist
-		if len(def.BeforeEach) > 0 {
+		if started && len(def.BeforeEach) > 0 {
 			r.Logger.Debug("Executing BeforeEach hooks", zap.Strings("hooks", def.BeforeEach))
-			hookVars, err := r.executeHooks(def.BeforeEach, suiteVars)
+			hookVars, err := r.executeHooks(ctx, def.BeforeEach, suiteVars)
 			if err != nil {
 				r.Logger.Error("Error executing BeforeEach hooks", zap.Error(err))
 				// We continue execution despite hook errors
@@ -313,12 +443,52 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 		// Set up the suite with variables
 		// Pass variables to suite
 		suite.Variables = suiteVars
+		suite.Interpolator = interpolator
+
+		// A suite's defaults apply to its auth and timeout unless it sets them, and its
+		// requests use the definition's defaults merged with its own
+		if suite.Defaults != nil {
+			if suite.Auth == nil {
+				suite.Auth = suite.Defaults.Auth
+			}
+			if suite.Timeout == "" {
+				suite.Timeout = suite.Defaults.Timeout
+			}
+		}
+		suite.Defaults = def.Defaults.Merge(suite.Defaults)
+
+		// Suites inherit the definition's auth and signing unless they set their own
+		if suite.Auth == nil {
+			suite.Auth = def.Auth
+		}
+		if suite.Sign == nil {
+			suite.Sign = def.Sign
+		}
+		if suite.Timeout == "" {
+			suite.Timeout = def.Timeout
+		}
+		if suite.Cookies == nil {
+			suite.Cookies = def.Cookies
+		}
+		suite.Jar = r.suiteCookieJar(def, &suite, &definitionJar, interpolator)
+		suite.Secrets = r.Secrets
+		suite.Tokens = r.Tokens
+
+		// Apply the runner's strict variables setting unless the suite overrides it
+		if _, ok := suite.Config["strict"]; !ok {
+			suiteConfig := make(map[string]interface{}, len(suite.Config)+1)
+			for k, v := range suite.Config {
+				suiteConfig[k] = v
+			}
+			suiteConfig["strict"] = r.StrictVariables
+			suite.Config = suiteConfig
+		}
 
 		// Execute the test suite
 		r.Logger.Debug(fmt.Sprintf("executing test suite: %s", suite.Name))
-		r.Logger.Debug("suite variables", zap.Any("variables", suite.Variables))
+		r.Logger.Debug("suite variables", zap.Any("variables", r.Secrets.RedactVariables(suite.Variables)))
 
-		suiteResult, err := suite.Run(r.Logger, r.HttpClient)
+		suiteResult, err := suite.Run(ctx, r.Logger, client)
 		if err != nil {
 			r.Logger.Error("error executing test suite", zap.Error(err))
 		}
@@ -327,9 +497,9 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 		suiteResult.Variables = suite.Variables
 
 		// Execute AfterEach hooks if they exist
-		if len(def.AfterEach) > 0 {
+		if started && len(def.AfterEach) > 0 {
 			r.Logger.Debug("Executing AfterEach hooks", zap.Strings("hooks", def.AfterEach))
-			_, err := r.executeHooks(def.AfterEach, suite.Variables)
+			_, err := r.executeHooks(teardownCtx, def.AfterEach, suite.Variables)
 			if err != nil {
 				r.Logger.Error("Error executing AfterEach hooks", zap.Error(err))
 				// We continue execution despite hook errors
@@ -340,9 +510,9 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 	}
 
 	// Execute AfterAll hooks if they exist
-	if len(def.AfterAll) > 0 {
+	if started && len(def.AfterAll) > 0 {
 		r.Logger.Debug("Executing AfterAll hooks", zap.Strings("hooks", def.AfterAll))
-		_, err := r.executeHooks(def.AfterAll, def.Variables)
+		_, err := r.executeHooks(teardownCtx, def.AfterAll, def.Variables)
 		if err != nil {
 			r.Logger.Error("Error executing AfterAll hooks", zap.Error(err))
 			// We continue execution despite hook errors
@@ -351,3 +521,101 @@ func (r *Runner) executeTestDefinition(def *tests.TestDefinition) (tests.TestDef
 
 	return result, nil
 }
+
+// definitionClientOptions returns the HTTP client options for a test definition with its
+// own TLS, proxy, resolve or HTTP version configuration, created from a copy of the
+// runner's client options so the shared client is never changed. It returns nil when the
+// definition has none of them.
+func (r *Runner) definitionClientOptions(def *tests.TestDefinition, interpolator *tests.Interpolator) *easyreq.HttpClientOptions {
+	if def.TLS == nil && def.Proxy == nil && len(def.Resolve) == 0 && def.HTTPVersion == "" {
+		return nil
+	}
+
+	if r.HttpClientOptions == nil {
+		r.Logger.Warn("Ignoring tls, proxy, resolve and http_version configuration, no HTTP client options to create a client from", zap.String("definition", def.Name))
+		return nil
+	}
+
+	opts := r.HttpClientOptions.Clone()
+
+	if def.TLS != nil {
+		tlsOptions, err := def.TLS.Options(interpolator, def.Variables)
+		if err != nil {
+			r.Logger.Error("Error interpolating tls configuration", zap.Error(err))
+			// Continue execution despite interpolation errors
+		}
+		opts.TLS = tlsOptions
+	}
+
+	if def.Proxy != nil {
+		proxyOptions, err := def.Proxy.Options(interpolator, def.Variables)
+		if err != nil {
+			r.Logger.Error("Error interpolating proxy configuration", zap.Error(err))
+		}
+		opts.Proxy = proxyOptions
+	}
+
+	// The definition's resolve entries are added to the ones set with --resolve
+	if len(def.Resolve) > 0 {
+		resolve, err := tests.ResolveOptions(def.Resolve, interpolator, def.Variables)
+		if err != nil {
+			r.Logger.Error("Error interpolating resolve configuration", zap.Error(err))
+		}
+		if opts.Resolve == nil {
+			opts.Resolve = make(map[string]string, len(resolve))
+		}
+		for host, address := range resolve {
+			opts.Resolve[host] = address
+		}
+	}
+
+	if def.HTTPVersion != "" {
+		opts.HTTPVersion = def.HTTPVersion
+	}
+
+	return opts
+}
+
+// suiteCookieJar returns the cookie jar for a suite, or nil when cookies are not kept.
+// definitionJar holds the jar shared by the suites of the definition, created when the
+// first suite needs it. Jars start with the definition's cookies, followed by the suite's
+// own cookies. A suite's own cookies are only added to a jar the suite owns, so they are
+// never sent by other suites.
+func (r *Runner) suiteCookieJar(def *tests.TestDefinition, suite *tests.TestSuite, definitionJar *http.CookieJar, interpolator *tests.Interpolator) http.CookieJar {
+	ownCookies := suite.Cookies != def.Cookies && len(suite.Cookies.Set) > 0
+
+	var jar http.CookieJar
+	switch suite.Cookies.Scope() {
+	case tests.CookieJarNone:
+		return nil
+	case tests.CookieJarDefinition:
+		if *definitionJar == nil {
+			*definitionJar = tests.NewCookieJar()
+			if err := def.Cookies.SeedJar(*definitionJar, interpolator, def.Variables); err != nil {
+				r.Logger.Error("Error setting definition cookies", zap.Error(err))
+			}
+		}
+		if !ownCookies {
+			return *definitionJar
+		}
+		// The suite shares cookies set by responses, but keeps its own cookies to itself
+		own := tests.NewCookieJar()
+		if err := suite.Cookies.SeedJar(own, interpolator, suite.Variables); err != nil {
+			r.Logger.Error("Error setting suite cookies", zap.Error(err))
+		}
+		return tests.NewOverlayCookieJar(own, *definitionJar)
+	case tests.CookieJarSuite:
+		jar = tests.NewCookieJar()
+		if err := def.Cookies.SeedJar(jar, interpolator, suite.Variables); err != nil {
+			r.Logger.Error("Error setting definition cookies", zap.Error(err))
+		}
+	}
+
+	if ownCookies {
+		if err := suite.Cookies.SeedJar(jar, interpolator, suite.Variables); err != nil {
+			r.Logger.Error("Error setting suite cookies", zap.Error(err))
+		}
+	}
+
+	return jar
+}
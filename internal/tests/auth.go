@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+// Supported authentication types
+const (
+	AuthTypeNone   = "none"
+	AuthTypeBasic  = "basic"
+	AuthTypeBearer = "bearer"
+	AuthTypeAPIKey = "api_key"
+	AuthTypeDigest = "digest"
+	AuthTypeOAuth2 = "oauth2"
+)
+
+// Auth configures how requests are authenticated. It can be set on a test definition, a
+// suite or a request; suites inherit the definition's auth and requests inherit the suite's
+// auth unless they set their own. Use type none to send a request without inherited auth.
+type Auth struct {
+	// Type is the authentication scheme: basic, bearer, api_key, digest, oauth2 or none
+	Type string `yaml:"type" json:"type"`
+	// Username for basic, digest and OAuth2 password authentication
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// Password for basic, digest and OAuth2 password authentication
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	// Token for bearer authentication
+	Token string `yaml:"token,omitempty" json:"token,omitempty"`
+	// Key is the header or query parameter name for api_key authentication
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+	// Value is the API key for api_key authentication
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// In is where the API key is sent: header (default) or query
+	In string `yaml:"in,omitempty" json:"in,omitempty"`
+	// TokenURL is the OAuth2 token endpoint
+	TokenURL string `yaml:"token_url,omitempty" json:"token_url,omitempty"`
+	// ClientID is the OAuth2 client ID
+	ClientID string `yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	// ClientSecret is the OAuth2 client secret
+	ClientSecret string `yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	// Scopes are the OAuth2 scopes to request
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+	// Grant is the OAuth2 grant: client_credentials (default) or password
+	Grant string `yaml:"grant,omitempty" json:"grant,omitempty"`
+	// ClientAuth is how OAuth2 client credentials are sent: header (default, HTTP Basic) or body
+	ClientAuth string `yaml:"client_auth,omitempty" json:"client_auth,omitempty"`
+}
+
+// Validate checks that the fields required by the auth type are set
+func (a *Auth) Validate() error {
+	switch strings.ToLower(a.Type) {
+	case AuthTypeNone:
+	case AuthTypeBasic, AuthTypeDigest:
+		if a.Username == "" {
+			return fmt.Errorf("%s auth requires a username", a.Type)
+		}
+	case AuthTypeBearer:
+		if a.Token == "" {
+			return fmt.Errorf("bearer auth requires a token")
+		}
+	case AuthTypeOAuth2:
+		if a.TokenURL == "" {
+			return fmt.Errorf("oauth2 auth requires a token_url")
+		}
+		if a.ClientID == "" {
+			return fmt.Errorf("oauth2 auth requires a client_id")
+		}
+		switch a.grant() {
+		case OAuth2GrantClientCredentials:
+		case OAuth2GrantPassword:
+			if a.Username == "" {
+				return fmt.Errorf("oauth2 password grant requires a username")
+			}
+		default:
+			return fmt.Errorf("unsupported oauth2 grant: %s", a.Grant)
+		}
+	case AuthTypeAPIKey:
+		if a.Key == "" {
+			return fmt.Errorf("api_key auth requires a key")
+		}
+		if in := strings.ToLower(a.In); in != "" && in != "header" && in != "query" {
+			return fmt.Errorf("api_key auth can only be sent in header or query, got %s", a.In)
+		}
+	default:
+		return fmt.Errorf("unsupported auth type: %s", a.Type)
+	}
+	return nil
+}
+
+// interpolate interpolates the fields of the auth configuration in place
+func (a *Auth) interpolate(in *interpolation) error {
+	for _, field := range a.fields() {
+		interpolated, err := in.value(*field.value, "request.auth."+field.name)
+		if err != nil {
+			return fmt.Errorf("error interpolating auth %s: %w", field.name, err)
+		}
+		*field.value = interpolated
+	}
+
+	if len(a.Scopes) > 0 {
+		scopes := make([]string, len(a.Scopes))
+		for i, scope := range a.Scopes {
+			interpolated, err := in.value(scope, "request.auth.scopes")
+			if err != nil {
+				return fmt.Errorf("error interpolating auth scopes: %w", err)
+			}
+			scopes[i] = interpolated
+		}
+		a.Scopes = scopes
+	}
+	return nil
+}
+
+// configField is a named auth or signing field that supports interpolation
+type configField struct {
+	name  string
+	value *string
+}
+
+// fields returns the auth fields that support interpolation
+func (a *Auth) fields() []configField {
+	return []configField{
+		{"username", &a.Username},
+		{"password", &a.Password},
+		{"token", &a.Token},
+		{"key", &a.Key},
+		{"value", &a.Value},
+		{"token_url", &a.TokenURL},
+		{"client_id", &a.ClientID},
+		{"client_secret", &a.ClientSecret},
+	}
+}
+
+// credentials returns the secret values of the auth configuration
+func (a *Auth) credentials() []string {
+	return []string{a.Password, a.Token, a.Value, a.ClientSecret}
+}
+
+// apply adds the credentials to the request parameters. token is the OAuth2 access token
+// for oauth2 auth.
+func (a *Auth) apply(params *easyreq.RequestParams, token string) {
+	switch strings.ToLower(a.Type) {
+	case AuthTypeOAuth2:
+		params.Headers["Authorization"] = "Bearer " + token
+	case AuthTypeBasic:
+		credentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		params.Headers["Authorization"] = "Basic " + credentials
+	case AuthTypeBearer:
+		params.Headers["Authorization"] = "Bearer " + a.Token
+	case AuthTypeAPIKey:
+		if strings.EqualFold(a.In, "query") {
+			if params.Query == nil {
+				params.Query = make(map[string]interface{})
+			}
+			params.Query[a.Key] = a.Value
+		} else {
+			params.Headers[a.Key] = a.Value
+		}
+	case AuthTypeDigest:
+		params.Digest = &easyreq.DigestAuth{
+			Username: a.Username,
+			Password: a.Password,
+		}
+	}
+}
+
+// effectiveAuth returns the auth configuration for a request: the request's own, or the
+// inherited one. The result is a copy that can be interpolated without changing the test
+// definition.
+func effectiveAuth(requestAuth *Auth, inherited *Auth) *Auth {
+	auth := requestAuth
+	if auth == nil {
+		auth = inherited
+	}
+	if auth == nil || strings.EqualFold(auth.Type, AuthTypeNone) {
+		return nil
+	}
+
+	copied := *auth
+	return &copied
+}
+
+// isOAuth2 reports whether the auth configuration uses OAuth2
+func (a *Auth) isOAuth2() bool {
+	return a != nil && strings.EqualFold(a.Type, AuthTypeOAuth2)
+}
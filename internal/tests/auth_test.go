@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+// digestTestHandler accepts requests answering its digest challenge for user:pass
+func digestTestHandler(w http.ResponseWriter, r *http.Request) {
+	const realm, nonce = "test", "abc123"
+
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Digest ") {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", nonce="%s", qop="auth", algorithm=MD5`, realm, nonce))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	params := make(map[string]string)
+	for _, part := range strings.Split(strings.TrimPrefix(authorization, "Digest "), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		params[key] = strings.Trim(value, `"`)
+	}
+
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	ha1 := md5hex("user:" + realm + ":pass")
+	ha2 := md5hex(r.Method + ":" + params["uri"])
+	expected := md5hex(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], params["qop"], ha2}, ":"))
+
+	if params["username"] != "user" || params["response"] != expected {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestExecCase_Auth(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/digest" {
+			digestTestHandler(w, r)
+			return
+		}
+		received = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suiteAuth := &Auth{Type: "bearer", Token: "${token}"}
+
+	tests := []struct {
+		name   string
+		auth   *Auth
+		header string
+		check  func(t *testing.T, r *http.Request)
+	}{
+		{
+			name: "Inherited bearer",
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer suite-token" {
+					t.Errorf("expected inherited bearer token, got %q", got)
+				}
+			},
+		},
+		{
+			name: "Basic overrides inherited auth",
+			auth: &Auth{Type: "basic", Username: "user", Password: "pass"},
+			check: func(t *testing.T, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				if !ok || username != "user" || password != "pass" {
+					t.Errorf("expected basic auth user:pass, got %q", r.Header.Get("Authorization"))
+				}
+			},
+		},
+		{
+			name: "API key in header",
+			auth: &Auth{Type: "api_key", Key: "X-Api-Key", Value: "key-123"},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("X-Api-Key"); got != "key-123" {
+					t.Errorf("expected API key header, got %q", got)
+				}
+				if got := r.Header.Get("Authorization"); got != "" {
+					t.Errorf("expected no inherited Authorization header, got %q", got)
+				}
+			},
+		},
+		{
+			name: "API key in query",
+			auth: &Auth{Type: "api_key", Key: "api_key", Value: "key-123", In: "query"},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.URL.Query().Get("api_key"); got != "key-123" {
+					t.Errorf("expected API key query parameter, got %q", got)
+				}
+			},
+		},
+		{
+			name: "None disables inherited auth",
+			auth: &Auth{Type: "none"},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "" {
+					t.Errorf("expected no Authorization header, got %q", got)
+				}
+			},
+		},
+		{
+			name:   "Request header takes precedence",
+			header: "Bearer invalid",
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer invalid" {
+					t.Errorf("expected request header, got %q", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			suite := &TestSuite{
+				Auth:      suiteAuth,
+				Variables: map[string]Variable{"token": {Type: "string", Value: "suite-token"}},
+				Secrets:   NewSecretStore(),
+			}
+			testCase := &TestCase{
+				Title:   tt.name,
+				Request: Request{Method: "GET", URL: server.URL + "/resource", Auth: tt.auth},
+			}
+			if tt.header != "" {
+				testCase.Request.Headers = []RequestHeader{{Key: "authorization", Value: tt.header}}
+			}
+
+			if _, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if received == nil {
+				t.Fatal("expected request to be received")
+			}
+			tt.check(t, received)
+		})
+	}
+
+	if suiteAuth.Token != "${token}" {
+		t.Errorf("expected suite auth not to be modified by interpolation, got %q", suiteAuth.Token)
+	}
+}
+
+func TestExecCase_DigestAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(digestTestHandler))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+
+	for _, tt := range []struct {
+		password string
+		passed   bool
+	}{
+		{"pass", true},
+		{"wrong", false},
+	} {
+		suite := &TestSuite{}
+		testCase := &TestCase{
+			Title: "digest",
+			Request: Request{
+				Method:     "POST",
+				URL:        server.URL + "/digest?x=1",
+				Body:       RequestBody{Type: "json", Data: map[string]interface{}{"a": 1}},
+				Auth:       &Auth{Type: "digest", Username: "user", Password: tt.password},
+				Assertions: map[string]interface{}{"status": 200},
+			},
+		}
+
+		result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Passed != tt.passed {
+			t.Errorf("password %q: expected passed=%v, got %v (%v)", tt.password, tt.passed, result.Passed, result.FailureReasons)
+		}
+	}
+}
+
+func TestAuth_Validate(t *testing.T) {
+	tests := []struct {
+		auth      Auth
+		expectErr bool
+	}{
+		{Auth{Type: "basic", Username: "user"}, false},
+		{Auth{Type: "basic"}, true},
+		{Auth{Type: "bearer"}, true},
+		{Auth{Type: "api_key", Key: "X-Api-Key", In: "cookie"}, true},
+		{Auth{Type: "none"}, false},
+		{Auth{Type: "kerberos"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.auth.Validate(); (err != nil) != tt.expectErr {
+			t.Errorf("Validate(%+v) error = %v, expectErr %v", tt.auth, err, tt.expectErr)
+		}
+	}
+}
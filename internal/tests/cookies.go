@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+// Cookie jar scopes
+const (
+	CookieJarNone       = "none"
+	CookieJarDefinition = "definition"
+	CookieJarSuite      = "suite"
+)
+
+// CookieConfig enables a cookie jar, so cookies set by responses are sent with later
+// requests. It can be set on a test definition or a suite; suites inherit the
+// definition's configuration unless they set their own.
+type CookieConfig struct {
+	// Jar is the scope of the cookie jar: definition shares one jar between all suites of
+	// the test definition, suite gives every suite its own jar and none disables cookies.
+	// Defaults to definition.
+	Jar string `yaml:"jar" json:"jar"`
+	// Set are cookies added to the jar before the first request
+	Set []SeedCookie `yaml:"set" json:"set"`
+}
+
+// SeedCookie is a cookie added to the jar before the first request, e.g. an existing
+// session. Its fields support variables.
+type SeedCookie struct {
+	// Name of the cookie
+	Name string `yaml:"name" json:"name"`
+	// Value of the cookie
+	Value string `yaml:"value" json:"value"`
+	// URL the cookie is sent to, e.g. "${base_url}". Cookies are sent to the URL's host
+	// and paths below the URL's path.
+	URL string `yaml:"url" json:"url"`
+}
+
+// Validate checks the cookie configuration
+func (c *CookieConfig) Validate() error {
+	switch c.Jar {
+	case "", CookieJarNone, CookieJarDefinition, CookieJarSuite:
+	default:
+		return fmt.Errorf("unsupported cookie jar scope: %s", c.Jar)
+	}
+
+	for _, cookie := range c.Set {
+		if cookie.Name == "" || cookie.URL == "" {
+			return fmt.Errorf("cookies to set require a name and url")
+		}
+	}
+	return nil
+}
+
+// Scope returns the scope of the cookie jar, or CookieJarNone for a nil configuration
+func (c *CookieConfig) Scope() string {
+	if c == nil {
+		return CookieJarNone
+	}
+	if c.Jar == "" {
+		return CookieJarDefinition
+	}
+	return c.Jar
+}
+
+// NewCookieJar returns an empty cookie jar
+func NewCookieJar() http.CookieJar {
+	// cookiejar.New only fails for invalid options
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+// NewOverlayCookieJar returns a cookie jar for a suite that shares the definition's jar
+// but has cookies of its own, such as the suite's seeded cookies. Cookies set by responses
+// are stored in both jars, so later suites see them, while cookies added to own directly
+// are only sent with the suite's requests. own takes precedence for cookies with the same
+// name.
+func NewOverlayCookieJar(own http.CookieJar, shared http.CookieJar) http.CookieJar {
+	return &overlayJar{own: own, shared: shared}
+}
+
+type overlayJar struct {
+	own    http.CookieJar
+	shared http.CookieJar
+}
+
+// SetCookies stores cookies set by a response in both jars
+func (j *overlayJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.own.SetCookies(u, cookies)
+	j.shared.SetCookies(u, cookies)
+}
+
+// Cookies returns the cookies of the own jar, followed by the shared jar's cookies with
+// other names
+func (j *overlayJar) Cookies(u *url.URL) []*http.Cookie {
+	cookies := j.own.Cookies(u)
+	names := make(map[string]bool, len(cookies))
+	for _, cookie := range cookies {
+		names[cookie.Name] = true
+	}
+	for _, cookie := range j.shared.Cookies(u) {
+		if !names[cookie.Name] {
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies
+}
+
+// SeedJar interpolates the cookies to set and adds them to the jar
+func (c *CookieConfig) SeedJar(jar http.CookieJar, ip *Interpolator, variables map[string]Variable) error {
+	if c == nil || jar == nil {
+		return nil
+	}
+
+	for _, seed := range c.Set {
+		name, err := ip.InterpolateVariables(seed.Name, variables)
+		if err != nil {
+			return fmt.Errorf("error interpolating cookie name: %w", err)
+		}
+		value, err := ip.InterpolateVariables(seed.Value, variables)
+		if err != nil {
+			return fmt.Errorf("error interpolating cookie %s: %w", name, err)
+		}
+		rawURL, err := ip.InterpolateVariables(seed.URL, variables)
+		if err != nil {
+			return fmt.Errorf("error interpolating url of cookie %s: %w", name, err)
+		}
+
+		cookieURL, err := url.Parse(rawURL)
+		if err != nil || cookieURL.Host == "" {
+			return fmt.Errorf("invalid url for cookie %s: %s", name, rawURL)
+		}
+
+		path := cookieURL.Path
+		if path == "" {
+			path = "/"
+		}
+		jar.SetCookies(cookieURL, []*http.Cookie{{Name: name, Value: value, Path: path}})
+	}
+	return nil
+}
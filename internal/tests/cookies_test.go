@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestCookieConfig(t *testing.T) {
+	if scope := (*CookieConfig)(nil).Scope(); scope != CookieJarNone {
+		t.Errorf("expected no jar without a configuration, got %s", scope)
+	}
+	if scope := (&CookieConfig{}).Scope(); scope != CookieJarDefinition {
+		t.Errorf("expected a definition jar by default, got %s", scope)
+	}
+	if err := (&CookieConfig{Jar: "case"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unsupported scope")
+	}
+	if err := (&CookieConfig{Set: []SeedCookie{{Name: "session"}}}).Validate(); err == nil {
+		t.Errorf("expected an error for a cookie without a url")
+	}
+
+	config := &CookieConfig{Set: []SeedCookie{{Name: "session", Value: "${session_id}", URL: "${base_url}/api"}}}
+	variables := map[string]Variable{
+		"session_id": {Type: "string", Value: "abc123"},
+		"base_url":   {Type: "string", Value: "https://example.com"},
+	}
+	jar := NewCookieJar()
+	if err := config.SeedJar(jar, nil, variables); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apiURL, _ := url.Parse("https://example.com/api/users")
+	if cookies := jar.Cookies(apiURL); len(cookies) != 1 || cookies[0].Value != "abc123" {
+		t.Errorf("expected the seeded cookie to be sent to the API, got %v", cookies)
+	}
+	otherURL, _ := url.Parse("https://example.com/login")
+	if cookies := jar.Cookies(otherURL); len(cookies) != 0 {
+		t.Errorf("expected the seeded cookie not to be sent outside its path, got %v", cookies)
+	}
+}
+
+func TestOverlayCookieJar(t *testing.T) {
+	u, _ := url.Parse("https://example.com/")
+	shared := NewCookieJar()
+	shared.SetCookies(u, []*http.Cookie{{Name: "session", Value: "shared"}, {Name: "theme", Value: "dark"}})
+
+	own := NewCookieJar()
+	own.SetCookies(u, []*http.Cookie{{Name: "session", Value: "seeded"}})
+	jar := NewOverlayCookieJar(own, shared)
+
+	values := func(cookies []*http.Cookie) map[string]string {
+		result := make(map[string]string)
+		for _, cookie := range cookies {
+			result[cookie.Name] = cookie.Value
+		}
+		return result
+	}
+
+	if got := values(jar.Cookies(u)); got["session"] != "seeded" || got["theme"] != "dark" || len(got) != 2 {
+		t.Errorf("expected the suite's cookie to take precedence over the shared ones, got %v", got)
+	}
+
+	// Cookies set by responses are shared, the suite's own cookies are not
+	jar.SetCookies(u, []*http.Cookie{{Name: "csrf", Value: "xyz"}})
+	if got := values(shared.Cookies(u)); got["session"] != "shared" || got["csrf"] != "xyz" {
+		t.Errorf("expected response cookies in the shared jar without the suite's own, got %v", got)
+	}
+	if got := values(jar.Cookies(u)); got["csrf"] != "xyz" {
+		t.Errorf("expected response cookies to be sent by the suite, got %v", got)
+	}
+}
+
+func TestRun_CookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/", HttpOnly: true, MaxAge: 3600})
+			w.WriteHeader(http.StatusOK)
+		case "/me":
+			cookie, err := r.Cookie("session")
+			if err != nil || cookie.Value != "s3cr3t" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{
+		Cases: []TestCase{
+			{
+				Title: "login",
+				Request: Request{
+					Method: "GET",
+					URL:    server.URL + "/login",
+					Assertions: map[string]interface{}{
+						"status":  200,
+						"cookies": map[string]interface{}{"session": map[string]interface{}{"http_only": true, "expires_in": ">= 59m"}},
+					},
+				},
+			},
+		},
+		Secrets: NewSecretStore(),
+	}
+
+	// Without a jar the session cookie is not sent
+	me := TestCase{Title: "me", Request: Request{Method: "GET", URL: server.URL + "/me", Assertions: map[string]interface{}{"status": 200}}}
+	suite.Cases = append(suite.Cases, me)
+	result, err := suite.Run(context.Background(), logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Cases["login"].Passed {
+		t.Errorf("expected cookie assertions to pass: %v", result.Cases["login"].FailureReasons)
+	}
+	if result.Cases["me"].Passed {
+		t.Errorf("expected the session cookie not to be kept without a jar")
+	}
+
+	suite.Jar = NewCookieJar()
+	result, err = suite.Run(context.Background(), logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Cases["me"].Passed {
+		t.Errorf("expected the session cookie to be sent from the jar: %v", result.Cases["me"].FailureReasons)
+	}
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Defaults are request settings for every test case of a test definition or suite. Suites
+// inherit the definition's defaults and override them setting by setting, and test cases
+// override them with their own settings.
+type Defaults struct {
+	// BaseURL is joined with relative request URLs, e.g. "/users". Absolute URLs are sent
+	// as they are.
+	BaseURL string `yaml:"base_url" json:"base_url"`
+	// Headers are sent with every request, unless the request sets or removes them
+	Headers []RequestHeader `yaml:"headers" json:"headers"`
+	// Query parameters are added to every request, unless the request URL or query sets them
+	Query map[string]string `yaml:"query" json:"query"`
+	// Timeout for each request, unless the definition, suite or test case sets a timeout
+	Timeout string `yaml:"timeout" json:"timeout"`
+	// Auth for every request, unless the definition, suite or request sets its own
+	Auth *Auth `yaml:"auth" json:"auth"`
+	// BodyType is the type of request bodies that do not set one, e.g. json
+	BodyType string `yaml:"body_type" json:"body_type"`
+}
+
+// Validate checks the defaults
+func (d *Defaults) Validate() error {
+	if _, err := ParseTimeout(d.Timeout); err != nil {
+		return err
+	}
+
+	if d.Auth != nil {
+		if err := d.Auth.Validate(); err != nil {
+			return fmt.Errorf("invalid auth: %w", err)
+		}
+	}
+
+	for _, h := range d.Headers {
+		if h.Remove && h.Value != "" {
+			return fmt.Errorf("header %s cannot have both a value and remove", h.Key)
+		}
+	}
+	return nil
+}
+
+// Merge returns the defaults with the settings of override taking precedence. Headers and
+// query parameters are merged by name. Either defaults can be nil.
+func (d *Defaults) Merge(override *Defaults) *Defaults {
+	if d == nil {
+		return override
+	}
+	if override == nil {
+		return d
+	}
+
+	merged := *d
+	if override.BaseURL != "" {
+		merged.BaseURL = override.BaseURL
+	}
+	if override.Timeout != "" {
+		merged.Timeout = override.Timeout
+	}
+	if override.Auth != nil {
+		merged.Auth = override.Auth
+	}
+	if override.BodyType != "" {
+		merged.BodyType = override.BodyType
+	}
+
+	// Headers set by override replace all values the defaults have for them
+	merged.Headers = nil
+	for _, h := range d.Headers {
+		if !containsHeader(override.Headers, h.Key) {
+			merged.Headers = append(merged.Headers, h)
+		}
+	}
+	merged.Headers = append(merged.Headers, override.Headers...)
+
+	merged.Query = make(map[string]string, len(d.Query)+len(override.Query))
+	for k, v := range d.Query {
+		merged.Query[k] = v
+	}
+	for k, v := range override.Query {
+		merged.Query[k] = v
+	}
+
+	return &merged
+}
+
+// containsHeader reports whether headers has a header named key
+func containsHeader(headers []RequestHeader, key string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDefaults merges the defaults into the request before it is interpolated, so the
+// base URL and headers are interpolated for each request like the request's own settings.
+// Headers the request sets or removes replace the default headers with the same name,
+// query parameters already in the request URL or query are not added, and the body type
+// is only set for bodies without one.
+func (d *Defaults) applyDefaults(request *Request) {
+	if d == nil {
+		return
+	}
+
+	request.BaseURL = d.BaseURL
+
+	// Default headers are sent first, without the ones the request sets or removes
+	if len(d.Headers) > 0 {
+		headers := make([]RequestHeader, 0, len(d.Headers)+len(request.Headers))
+		for _, h := range d.Headers {
+			if !containsHeader(request.Headers, h.Key) {
+				headers = append(headers, h)
+			}
+		}
+		request.Headers = append(headers, request.Headers...)
+	}
+
+	if len(d.Query) > 0 {
+		var urlQuery url.Values
+		if parsed, err := url.Parse(request.URL); err == nil {
+			urlQuery = parsed.Query()
+		}
+
+		query := make(map[string]string, len(d.Query)+len(request.Query))
+		for k, v := range d.Query {
+			if !urlQuery.Has(k) {
+				query[k] = v
+			}
+		}
+		for k, v := range request.Query {
+			query[k] = v
+		}
+		request.Query = query
+	}
+
+	if request.Body.Type == "" && request.Body.Data != nil {
+		request.Body.Type = d.BodyType
+	}
+}
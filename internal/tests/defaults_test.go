@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestDefaults_Merge(t *testing.T) {
+	definition := &Defaults{
+		BaseURL:  "https://api.example.com",
+		Headers:  []RequestHeader{{Key: "Accept", Value: "application/json"}, {Key: "X-Tenant", Value: "acme"}},
+		Query:    map[string]string{"version": "1", "locale": "en"},
+		Timeout:  "5s",
+		BodyType: "json",
+	}
+	suite := &Defaults{
+		BaseURL: "https://admin.example.com",
+		Headers: []RequestHeader{{Key: "x-tenant", Value: "globex"}},
+		Query:   map[string]string{"version": "2"},
+	}
+
+	merged := definition.Merge(suite)
+	if merged.BaseURL != "https://admin.example.com" || merged.Timeout != "5s" || merged.BodyType != "json" {
+		t.Errorf("unexpected merged settings: %+v", merged)
+	}
+	expectedHeaders := []RequestHeader{{Key: "Accept", Value: "application/json"}, {Key: "x-tenant", Value: "globex"}}
+	if !reflect.DeepEqual(merged.Headers, expectedHeaders) {
+		t.Errorf("expected headers %v, got %v", expectedHeaders, merged.Headers)
+	}
+	if !reflect.DeepEqual(merged.Query, map[string]string{"version": "2", "locale": "en"}) {
+		t.Errorf("unexpected merged query: %v", merged.Query)
+	}
+	if definition.Query["version"] != "1" || len(definition.Headers) != 2 {
+		t.Errorf("expected the definition's defaults not to change")
+	}
+
+	if (*Defaults)(nil).Merge(suite) != suite || definition.Merge(nil) != definition {
+		t.Errorf("expected merging with nil defaults to return the other defaults")
+	}
+}
+
+func TestDefaults_ApplyDefaults(t *testing.T) {
+	defaults := &Defaults{
+		BaseURL: "${base_url}/v1",
+		Headers: []RequestHeader{
+			{Key: "Accept", Value: "application/json"},
+			{Key: "X-Tenant", Value: "acme"},
+			{Key: "X-Debug", Remove: true},
+		},
+	}
+	headers := []RequestHeader{{Key: "x-tenant", Value: "globex"}, {Key: "X-Trace", Value: "1"}}
+	request := Request{URL: "/users", Headers: headers}
+
+	defaults.applyDefaults(&request)
+
+	if request.BaseURL != "${base_url}/v1" {
+		t.Errorf("expected the base URL to be set for interpolation, got %s", request.BaseURL)
+	}
+	expected := []RequestHeader{
+		{Key: "Accept", Value: "application/json"},
+		{Key: "X-Debug", Remove: true},
+		{Key: "x-tenant", Value: "globex"},
+		{Key: "X-Trace", Value: "1"},
+	}
+	if !reflect.DeepEqual(request.Headers, expected) {
+		t.Errorf("expected headers %v, got %v", expected, request.Headers)
+	}
+	if len(headers) != 2 || headers[0].Value != "globex" {
+		t.Errorf("expected the test case headers not to change")
+	}
+}
+
+func TestExecCase_Defaults(t *testing.T) {
+	// The server echoes the path, query, headers and body it received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"path":   r.URL.Path,
+			"query":  r.URL.Query(),
+			"accept": r.Header.Get("Accept"),
+			"body":   string(body),
+		})
+	}))
+	defer server.Close()
+
+	defaults := &Defaults{
+		BaseURL:  "${server}/api",
+		Headers:  []RequestHeader{{Key: "Accept", Value: "application/json"}},
+		Query:    map[string]string{"tenant": "${tenant}", "page": "1"},
+		BodyType: "json",
+	}
+	client := easyreq.New(easyreq.NewOptions())
+
+	suite := &TestSuite{
+		Defaults:  defaults,
+		Variables: map[string]Variable{
+			"server": {Type: "string", Value: server.URL},
+			"tenant": {Type: "string", Value: "acme"},
+		},
+		Secrets:   NewSecretStore(),
+	}
+	testCase := &TestCase{
+		Title: "defaults",
+		Request: Request{
+			Method: "POST",
+			URL:    "/orders?page=3",
+			Query:  map[string]string{"sort": "desc"},
+			Body:   RequestBody{Data: `{"id": 1}`},
+			Assertions: map[string]interface{}{
+				"status": 200,
+				"body": map[string]interface{}{
+					"$.path":            "/api/orders",
+					"$.query.tenant[0]": "acme",
+					"$.query.page[0]":   "3",
+					"$.query.sort[0]":   "desc",
+					"$.accept":          "application/json",
+					"$.body":            `{"id":1}`,
+				},
+			},
+		},
+	}
+
+	result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected the defaults to be merged into the request: %v", result.FailureReasons)
+	}
+	if testCase.Request.Query["tenant"] != "" || testCase.Request.Body.Type != "" || testCase.Request.BaseURL != "" {
+		t.Errorf("expected the test case not to change")
+	}
+}
+
+func TestSuiteRun_DefaultsAreInterpolatedPerRequest(t *testing.T) {
+	var authorizations, requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		requestIDs = append(requestIDs, r.Header.Get("X-Request-ID"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "t0k3n"}`))
+	}))
+	defer server.Close()
+
+	suite := &TestSuite{
+		Name: "defaults",
+		Defaults: &Defaults{
+			BaseURL: "${server}",
+			Headers: []RequestHeader{
+				{Key: "Authorization", Value: "Bearer ${token}"},
+				{Key: "X-Request-ID", Value: "${uuid()}"},
+			},
+		},
+		Variables: map[string]Variable{
+			"server": {Type: "string", Value: server.URL},
+			"token":  {Type: "string", Value: "initial"},
+		},
+		Secrets: NewSecretStore(),
+		Cases: []TestCase{
+			{
+				Title: "login",
+				Request: Request{
+					Method: "POST",
+					URL:    "/login",
+					Export: RequestExport{Body: []BodyExport{{Path: "$.token", As: "token"}}},
+				},
+			},
+			{Title: "use", Request: Request{Method: "GET", URL: "/me"}},
+		},
+	}
+
+	result, err := suite.Run(context.Background(), logging.NewMockLogger(), easyreq.New(easyreq.NewOptions()))
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	for title, caseResult := range result.Cases {
+		if !caseResult.Passed {
+			t.Errorf("expected %s to pass: %v", title, caseResult.FailureReasons)
+		}
+	}
+
+	if !reflect.DeepEqual(authorizations, []string{"Bearer initial", "Bearer t0k3n"}) {
+		t.Errorf("expected the exported token in the second request, got %v", authorizations)
+	}
+	if len(requestIDs) != 2 || requestIDs[0] == "" || requestIDs[0] == requestIDs[1] {
+		t.Errorf("expected a new request ID for each request, got %v", requestIDs)
+	}
+
+	// Undefined variables in the defaults fail the test case in strict mode
+	delete(suite.Variables, "token")
+	suite.Cases = suite.Cases[1:]
+	result, err = suite.Run(context.Background(), logging.NewMockLogger(), easyreq.New(easyreq.NewOptions()))
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	if caseResult := result.Cases["use"]; !caseResult.Errored {
+		t.Errorf("expected an errored case for the undefined token, got %+v", caseResult)
+	}
+	if len(authorizations) != 2 {
+		t.Errorf("expected no request to be sent, got %v", authorizations)
+	}
+}
+
+func TestDefaults_Validate(t *testing.T) {
+	tests := []struct {
+		defaults  Defaults
+		expectErr bool
+	}{
+		{Defaults{BaseURL: "https://api.example.com", Timeout: "5s"}, false},
+		{Defaults{Timeout: "soon"}, true},
+		{Defaults{Auth: &Auth{Type: "bearer"}}, true},
+		{Defaults{Headers: []RequestHeader{{Key: "Accept", Value: "*/*", Remove: true}}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.defaults.Validate(); (err != nil) != tt.expectErr {
+			t.Errorf("Validate(%+v) error = %v, expectErr %v", tt.defaults, err, tt.expectErr)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvFileError describes a syntax error in an environment file
+type EnvFileError struct {
+	// Path is the path of the file, if known
+	Path string
+	// Line is the line number the error was found on, starting at 1
+	Line int
+	// Message describes the error
+	Message string
+}
+
+func (e *EnvFileError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("error in env file %s at line %d: %s", e.Path, e.Line, e.Message)
+	}
+	return fmt.Sprintf("error in env file at line %d: %s", e.Line, e.Message)
+}
+
+// EnvFileOptions controls how environment files are read
+type EnvFileOptions struct {
+	// Override lets values in files replace environment variables that are already set.
+	// When false, values that are already set win, including in ${OTHER} expansion.
+	Override bool
+	// Lookup returns the value of an environment variable that is already set. It is used
+	// for ${OTHER} expansion and when Override is false. Defaults to os.LookupEnv.
+	Lookup func(key string) (string, bool)
+}
+
+func (o EnvFileOptions) lookup(key string) (string, bool) {
+	if o.Lookup != nil {
+		return o.Lookup(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// ReadEnvFiles reads environment files in order, with later files overriding earlier ones,
+// and returns the combined values. Values can reference variables defined earlier in the
+// same or a previous file, or already set environment variables. Files that do not exist
+// are skipped.
+func ReadEnvFiles(paths []string, opts EnvFileOptions) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			// If file doesn't exist, skip it (not an error)
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("error opening env file: %w", err)
+		}
+
+		err = parseEnvFile(file, values, opts)
+		file.Close()
+		if err != nil {
+			if envErr, ok := err.(*EnvFileError); ok {
+				envErr.Path = path
+			}
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// ParseEnvFile parses environment file content in the dotenv format:
+//
+//	# Comments and blank lines are ignored
+//	export API_URL=https://api.example.com   # inline comment
+//	API_KEY='single quoted, taken literally'
+//	GREETING="double quoted\nwith escapes and ${API_URL} expansion"
+//	CERT="multiline values
+//	span lines inside quotes"
+func ParseEnvFile(r io.Reader, opts EnvFileOptions) (map[string]string, error) {
+	values := make(map[string]string)
+	if err := parseEnvFile(r, values, opts); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseEnvFile parses environment file content into values
+func parseEnvFile(r io.Reader, values map[string]string, opts EnvFileOptions) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("error reading env file: %w", err)
+	}
+
+	p := &envFileParser{
+		data:   strings.ReplaceAll(string(data), "\r\n", "\n"),
+		line:   1,
+		values: values,
+		opts:   opts,
+	}
+	return p.parse()
+}
+
+// envFileParser parses dotenv content
+type envFileParser struct {
+	data   string
+	pos    int
+	line   int
+	values map[string]string
+	opts   EnvFileOptions
+}
+
+func (p *envFileParser) errorf(line int, format string, args ...interface{}) error {
+	return &EnvFileError{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *envFileParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *envFileParser) peek() byte {
+	return p.data[p.pos]
+}
+
+// next consumes and returns the next character, keeping track of the line number
+func (p *envFileParser) next() byte {
+	c := p.data[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlanks skips spaces and tabs, but not newlines
+func (p *envFileParser) skipBlanks() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine skips to the start of the next line
+func (p *envFileParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *envFileParser) parse() error {
+	for {
+		// Skip blank lines and leading whitespace
+		for !p.eof() && strings.IndexByte(" \t\n", p.peek()) >= 0 {
+			p.next()
+		}
+		if p.eof() {
+			return nil
+		}
+
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		if err := p.parseEntry(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseEntry parses a single KEY=VALUE entry
+func (p *envFileParser) parseEntry() error {
+	line := p.line
+
+	key := p.parseKey()
+	if key == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipBlanks()
+		key = p.parseKey()
+	}
+	if key == "" {
+		return p.errorf(line, "expected a variable name, got %q", p.restOfLine())
+	}
+
+	p.skipBlanks()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf(line, "expected '=' after %s", key)
+	}
+	p.pos++
+	p.skipBlanks()
+
+	var value string
+	var err error
+	switch {
+	case p.eof():
+		value = ""
+	case p.peek() == '\'':
+		value, err = p.parseSingleQuoted()
+	case p.peek() == '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value = p.expand(p.parseUnquoted())
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only whitespace or a comment may follow a quoted value
+	p.skipBlanks()
+	if !p.eof() && p.peek() != '\n' {
+		if p.peek() != '#' {
+			return p.errorf(p.line, "unexpected characters after value of %s: %q", key, p.restOfLine())
+		}
+		p.skipLine()
+	}
+
+	// Already set environment variables win unless overriding is enabled
+	if !p.opts.Override {
+		if existing, ok := p.opts.lookup(key); ok {
+			value = existing
+		}
+	}
+
+	p.values[key] = value
+	return nil
+}
+
+// parseKey parses a variable name
+func (p *envFileParser) parseKey() string {
+	start := p.pos
+	for !p.eof() && isEnvKeyChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func isEnvKeyChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+		return true
+	}
+	return !first && ((c >= '0' && c <= '9') || c == '.')
+}
+
+// restOfLine returns the text up to the end of the current line, for error messages
+func (p *envFileParser) restOfLine() string {
+	end := strings.IndexByte(p.data[p.pos:], '\n')
+	if end < 0 {
+		return p.data[p.pos:]
+	}
+	return p.data[p.pos : p.pos+end]
+}
+
+// parseSingleQuoted parses a single quoted value, which is taken literally
+func (p *envFileParser) parseSingleQuoted() (string, error) {
+	line := p.line
+	p.next()
+
+	end := strings.IndexByte(p.data[p.pos:], '\'')
+	if end < 0 {
+		return "", p.errorf(line, "unterminated single quoted value")
+	}
+
+	value := p.data[p.pos : p.pos+end]
+	closing := p.pos + end
+	for p.pos <= closing {
+		p.next()
+	}
+	return value, nil
+}
+
+// parseDoubleQuoted parses a double quoted value, handling escape sequences, escaped
+// newlines and variable expansion
+func (p *envFileParser) parseDoubleQuoted() (string, error) {
+	line := p.line
+	p.next()
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(line, "unterminated double quoted value")
+		}
+
+		c := p.next()
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf(line, "unterminated double quoted value")
+			}
+			switch escaped := p.next(); escaped {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '\n':
+				// An escaped newline continues the value on the next line
+			case '"', '\\', '$':
+				sb.WriteByte(escaped)
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(escaped)
+			}
+		case '$':
+			sb.WriteString(p.parseReference())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+// parseUnquoted parses an unquoted value up to the end of the line or an inline comment
+func (p *envFileParser) parseUnquoted() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		// A # starts a comment when it follows whitespace
+		if p.peek() == '#' && (p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+	return strings.TrimRight(p.data[start:p.pos], " \t")
+}
+
+// parseReference parses a variable reference after a '$' in a double quoted value and
+// returns its expanded value
+func (p *envFileParser) parseReference() string {
+	ref, n := envReferenceAt(p.data[p.pos:])
+	if n == 0 {
+		return "$"
+	}
+	p.pos += n
+	return p.resolve(ref)
+}
+
+// expand expands the variable references in an unquoted value. "\$" is a literal dollar sign.
+func (p *envFileParser) expand(value string) string {
+	if !strings.ContainsRune(value, '$') {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '$':
+			sb.WriteByte('$')
+			i++
+		case value[i] == '$':
+			ref, n := envReferenceAt(value[i+1:])
+			if n == 0 {
+				sb.WriteByte('$')
+				continue
+			}
+			sb.WriteString(p.resolve(ref))
+			i += n
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
+// envReference is a parsed $NAME, ${NAME} or ${NAME:-default} reference
+type envReference struct {
+	name         string
+	defaultValue string
+	hasDefault   bool
+}
+
+// envReferenceAt parses the reference at the start of s, which follows a '$'. It returns
+// the number of characters consumed, or 0 if s does not start with a reference.
+func envReferenceAt(s string) (envReference, int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return envReference{}, 0
+		}
+		body := s[1:end]
+		ref := envReference{name: body}
+		if idx := strings.Index(body, ":-"); idx >= 0 {
+			ref = envReference{name: body[:idx], defaultValue: body[idx+2:], hasDefault: true}
+		}
+		if ref.name == "" {
+			return envReference{}, 0
+		}
+		return ref, end + 1
+	}
+
+	n := 0
+	for n < len(s) && isEnvKeyChar(s[n], n == 0) && s[n] != '.' {
+		n++
+	}
+	return envReference{name: s[:n]}, n
+}
+
+// resolve returns the value of a reference from earlier values or the environment.
+// Undefined references expand to their default, or to an empty string.
+func (p *envFileParser) resolve(ref envReference) string {
+	value, ok := p.values[ref.name]
+	if !ok {
+		value, ok = p.opts.lookup(ref.name)
+	}
+	if (!ok || value == "") && ref.hasDefault {
+		return ref.defaultValue
+	}
+	return value
+}
@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{
+			name:     "Plain values and comments",
+			input:    "# comment\n\nA=1\n  B = two  \n",
+			expected: map[string]string{"A": "1", "B": "two"},
+		},
+		{
+			name:     "Export prefix",
+			input:    "export API_URL=https://api.example.com\nexport=kept\n",
+			expected: map[string]string{"API_URL": "https://api.example.com", "export": "kept"},
+		},
+		{
+			name:     "Inline comments",
+			input:    "A=value # comment\nB=val#ue\nC=\"quoted # not a comment\" # comment\nD= # empty\n",
+			expected: map[string]string{"A": "value", "B": "val#ue", "C": "quoted # not a comment", "D": ""},
+		},
+		{
+			name:     "Quote stripping",
+			input:    "A='single'\nB=\"double\"\nC='it\"s'\nD=\"\"\nE=''\n",
+			expected: map[string]string{"A": "single", "B": "double", "C": "it\"s", "D": "", "E": ""},
+		},
+		{
+			name:     "Unmatched quotes in unquoted values are kept",
+			input:    "A=don't\nB=say \"hi\"\n",
+			expected: map[string]string{"A": "don't", "B": "say \"hi\""},
+		},
+		{
+			name:     "Escapes in double quotes",
+			input:    `A="line1\nline2\ttab \"quoted\" \\ \$HOME"` + "\n",
+			expected: map[string]string{"A": "line1\nline2\ttab \"quoted\" \\ $HOME"},
+		},
+		{
+			name:     "Escaped newline continues the value",
+			input:    "A=\"first \\\nsecond\"\n",
+			expected: map[string]string{"A": "first second"},
+		},
+		{
+			name:     "Multiline quoted values",
+			input:    "KEY=\"-----BEGIN-----\nabc\n-----END-----\"\nSINGLE='a\nb'\nNEXT=1\n",
+			expected: map[string]string{"KEY": "-----BEGIN-----\nabc\n-----END-----", "SINGLE": "a\nb", "NEXT": "1"},
+		},
+		{
+			name:  "Expansion",
+			input: "HOST=example.com\nURL=https://${HOST}/api\nALT=\"$HOST:8080\"\nLIT='${HOST}'\nDEF=${MISSING:-fallback}\nEMPTY=${MISSING}\nESC=\\$HOST\n",
+			expected: map[string]string{
+				"HOST":  "example.com",
+				"URL":   "https://example.com/api",
+				"ALT":   "example.com:8080",
+				"LIT":   "${HOST}",
+				"DEF":   "fallback",
+				"EMPTY": "",
+				"ESC":   "$HOST",
+			},
+		},
+		{
+			name:     "Windows line endings",
+			input:    "A=1\r\nB=\"2\"\r\n",
+			expected: map[string]string{"A": "1", "B": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := ParseEnvFile(strings.NewReader(tt.input), EnvFileOptions{Override: true, Lookup: noEnv})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(values) != len(tt.expected) {
+				t.Errorf("expected %d values, got %d: %v", len(tt.expected), len(values), values)
+			}
+			for k, want := range tt.expected {
+				if got := values[k]; got != want {
+					t.Errorf("%s = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEnvFile_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"Missing equals", "A=1\nINVALID\n", 2},
+		{"Invalid name", "A=1\n\n=value\n", 3},
+		{"Single quote only", "A='\n", 1},
+		{"Unterminated double quote", "A=1\nB=\"abc\nC=2\n", 2},
+		{"Text after quoted value", "A=\"abc\" def\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEnvFile(strings.NewReader(tt.input), EnvFileOptions{Override: true})
+			var envErr *EnvFileError
+			if !errors.As(err, &envErr) {
+				t.Fatalf("expected *EnvFileError, got %v", err)
+			}
+			if envErr.Line != tt.line {
+				t.Errorf("expected error on line %d, got %d (%v)", tt.line, envErr.Line, err)
+			}
+		})
+	}
+}
+
+func TestReadEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte("HOST=example.com\nPORT=80\nUSER=file-user\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("PORT=8080\nURL=http://${HOST}:${PORT}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"USER": "process-user"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	// Later files override earlier ones and can reference their values; missing files are skipped
+	values, err := ReadEnvFiles([]string{base, filepath.Join(dir, "missing"), local}, EnvFileOptions{Override: true, Lookup: lookup})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["PORT"] != "8080" || values["URL"] != "http://example.com:8080" || values["USER"] != "file-user" {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	// Without override, already set variables win
+	values, err = ReadEnvFiles([]string{base}, EnvFileOptions{Override: false, Lookup: lookup})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["USER"] != "process-user" {
+		t.Errorf("expected USER to keep the process value, got %q", values["USER"])
+	}
+
+	// Errors include the file path and line
+	bad := filepath.Join(dir, ".env.bad")
+	if err := os.WriteFile(bad, []byte("OK=1\nBROKEN\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadEnvFiles([]string{bad}, EnvFileOptions{})
+	if err == nil || !strings.Contains(err.Error(), bad) || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error with path and line, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"os"
+	"sync"
+)
+
+// Environment holds the environment variables available to ${env:...} references during a
+// run. Values loaded from environment files are kept here instead of being set in the
+// process environment, so they do not leak into other runs in the same process or into
+// child processes. Lookups fall back to the process environment.
+//
+// An Environment is safe for concurrent use, and a nil *Environment reads the process
+// environment only.
+type Environment struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewEnvironment creates an empty Environment
+func NewEnvironment() *Environment {
+	return &Environment{
+		values: make(map[string]string),
+	}
+}
+
+// Set sets the value of an environment variable for the run
+func (e *Environment) Set(key string, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.values[key] = value
+}
+
+// Lookup returns the value of an environment variable, falling back to the process
+// environment when it was not set on the Environment
+func (e *Environment) Lookup(key string) (string, bool) {
+	if e != nil {
+		e.mu.RLock()
+		value, ok := e.values[key]
+		e.mu.RUnlock()
+		if ok {
+			return value, true
+		}
+	}
+	return os.LookupEnv(key)
+}
+
+// Get returns the value of an environment variable, or an empty string if it is not set
+func (e *Environment) Get(key string) string {
+	value, _ := e.Lookup(key)
+	return value
+}
+
+// LoadFiles loads environment files in order, with later files overriding earlier ones.
+// Unless override is set, variables that are already set keep their values. See
+// ParseEnvFile for the file format.
+func (e *Environment) LoadFiles(paths []string, override bool) error {
+	values, err := ReadEnvFiles(paths, EnvFileOptions{Override: override, Lookup: e.Lookup})
+	if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		e.Set(key, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestEnvironment_Lookup(t *testing.T) {
+	t.Setenv("HTTPPROBE_TEST_PROCESS", "from-process")
+	t.Setenv("HTTPPROBE_TEST_SHADOWED", "from-process")
+
+	env := NewEnvironment()
+	env.Set("HTTPPROBE_TEST_SHADOWED", "from-env")
+	env.Set("HTTPPROBE_TEST_RUN_ONLY", "run-value")
+
+	if got := env.Get("HTTPPROBE_TEST_SHADOWED"); got != "from-env" {
+		t.Errorf("expected run value to take precedence, got %q", got)
+	}
+	if got := env.Get("HTTPPROBE_TEST_PROCESS"); got != "from-process" {
+		t.Errorf("expected fallback to the process environment, got %q", got)
+	}
+	if _, ok := os.LookupEnv("HTTPPROBE_TEST_RUN_ONLY"); ok {
+		t.Errorf("expected run values not to be set in the process environment")
+	}
+
+	var nilEnv *Environment
+	if got := nilEnv.Get("HTTPPROBE_TEST_PROCESS"); got != "from-process" {
+		t.Errorf("expected nil environment to read the process environment, got %q", got)
+	}
+}
+
+func TestEnvironment_LoadFiles(t *testing.T) {
+	t.Setenv("HTTPPROBE_TEST_KEEP", "from-process")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("HTTPPROBE_TEST_KEEP=from-file\nHTTPPROBE_TEST_LOADED=loaded\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := NewEnvironment()
+	if err := env.LoadFiles([]string{path}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := env.Get("HTTPPROBE_TEST_KEEP"); got != "from-process" {
+		t.Errorf("expected process value to be kept, got %q", got)
+	}
+	if got := env.Get("HTTPPROBE_TEST_LOADED"); got != "loaded" {
+		t.Errorf("expected loaded value, got %q", got)
+	}
+	if _, ok := os.LookupEnv("HTTPPROBE_TEST_LOADED"); ok {
+		t.Errorf("expected loaded values not to be set in the process environment")
+	}
+
+	override := NewEnvironment()
+	if err := override.LoadFiles([]string{path}, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := override.Get("HTTPPROBE_TEST_KEEP"); got != "from-file" {
+		t.Errorf("expected file value to override, got %q", got)
+	}
+}
+
+func TestInterpolateVariables_Environment(t *testing.T) {
+	staging := NewEnvironment()
+	staging.Set("HTTPPROBE_TEST_HOST", "staging.example.com")
+	production := NewEnvironment()
+	production.Set("HTTPPROBE_TEST_HOST", "api.example.com")
+
+	// Runs with different environments in the same process do not interfere
+	var wg sync.WaitGroup
+	for _, tc := range []struct {
+		env      *Environment
+		expected string
+	}{
+		{staging, "https://staging.example.com"},
+		{production, "https://api.example.com"},
+	} {
+		wg.Add(1)
+		go func(env *Environment, expected string) {
+			defer wg.Done()
+			ip := &Interpolator{Env: env}
+			for i := 0; i < 100; i++ {
+				result, err := ip.InterpolateVariables("https://${env:HTTPPROBE_TEST_HOST}", nil)
+				if err != nil || result != expected {
+					t.Errorf("expected %q, got %q (%v)", expected, result, err)
+					return
+				}
+			}
+		}(tc.env, tc.expected)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Word lists used by the fake data generators
+var (
+	fakeFirstNames = []string{
+		"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth",
+		"David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+		"Amara", "Chidi", "Sofia", "Lukas", "Mei", "Hiroshi", "Priya", "Arjun", "Fatima", "Omar",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee",
+		"Okafor", "Nwosu", "Rossi", "Müller", "Schmidt", "Tanaka", "Sato", "Patel", "Khan", "Haddad",
+	}
+	fakeEmailDomains = []string{"example.com", "example.org", "example.net", "test.example.com"}
+	fakeStreetNames  = []string{
+		"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake", "Hill", "Park",
+		"Sunset", "River", "Church", "Mill", "Station",
+	}
+	fakeStreetSuffixes = []string{"Street", "Avenue", "Road", "Lane", "Drive", "Court", "Way", "Boulevard"}
+	fakeCities         = []string{
+		"Springfield", "Riverside", "Fairview", "Franklin", "Greenville", "Bristol", "Clinton", "Madison",
+		"Georgetown", "Salem", "Oakland", "Ashland", "Burlington", "Manchester", "Milton",
+	}
+	fakeCountries = []string{
+		"United States", "United Kingdom", "Germany", "France", "Netherlands", "Nigeria", "Kenya", "Brazil",
+		"Canada", "Japan", "India", "Australia", "Spain", "Italy", "Sweden",
+	}
+	fakeCompanyPrefixes = []string{
+		"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli", "Vandelay", "Soylent", "Cyberdyne",
+		"Northwind", "Contoso", "Fabrikam", "Tyrell", "Wonka",
+	}
+	fakeCompanySuffixes = []string{"Inc", "LLC", "Ltd", "Group", "Holdings", "Labs", "Systems", "Industries"}
+	fakeLoremWords      = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+	}
+)
+
+// fakeFunctions holds the fake.* template functions, keyed by name
+var fakeFunctions = map[string]TemplateFunction{
+	"fake.firstName": func(ip *Interpolator, args []string) (string, error) {
+		return fakePick(ip.random(), fakeFirstNames), nil
+	},
+	"fake.lastName": func(ip *Interpolator, args []string) (string, error) {
+		return fakePick(ip.random(), fakeLastNames), nil
+	},
+	"fake.name": func(ip *Interpolator, args []string) (string, error) {
+		return fakePick(ip.random(), fakeFirstNames) + " " + fakePick(ip.random(), fakeLastNames), nil
+	},
+	"fake.username": func(ip *Interpolator, args []string) (string, error) {
+		return fakeUsername(ip.random()), nil
+	},
+	"fake.email":   processFakeEmailFunction,
+	"fake.phone":   processFakePhoneFunction,
+	"fake.address": processFakeAddressFunction,
+	"fake.street": func(ip *Interpolator, args []string) (string, error) {
+		return fakeStreet(ip.random()), nil
+	},
+	"fake.city": func(ip *Interpolator, args []string) (string, error) {
+		return fakePick(ip.random(), fakeCities), nil
+	},
+	"fake.country": func(ip *Interpolator, args []string) (string, error) {
+		return fakePick(ip.random(), fakeCountries), nil
+	},
+	"fake.zipCode": func(ip *Interpolator, args []string) (string, error) {
+		return fakeDigits(ip.random(), 5), nil
+	},
+	"fake.company": func(ip *Interpolator, args []string) (string, error) {
+		return fakePick(ip.random(), fakeCompanyPrefixes) + " " + fakePick(ip.random(), fakeCompanySuffixes), nil
+	},
+	"fake.iban":  processFakeIbanFunction,
+	"fake.lorem": processFakeLoremFunction,
+}
+
+// fakePick returns a random element of values
+func fakePick(rng *RandomSource, values []string) string {
+	return values[rng.Intn(len(values))]
+}
+
+// fakeDigits returns a string of n random digits
+func fakeDigits(rng *RandomSource, n int) string {
+	digits := make([]byte, n)
+	for i := range digits {
+		digits[i] = byte('0' + rng.Intn(10))
+	}
+	return string(digits)
+}
+
+// fakeUsername returns a lower case username with a random numeric suffix to keep it unique
+func fakeUsername(rng *RandomSource) string {
+	first := strings.ToLower(fakePick(rng, fakeFirstNames))
+	last := strings.ToLower(fakePick(rng, fakeLastNames))
+	return asciiOnly(first + "." + last + fakeDigits(rng, 4))
+}
+
+// asciiOnly removes any characters that are not valid in the local part of an email address
+func asciiOnly(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// fakeStreet returns a street address line, e.g. "42 Oak Avenue"
+func fakeStreet(rng *RandomSource) string {
+	return fmt.Sprintf("%d %s %s", 1+rng.Intn(9999), fakePick(rng, fakeStreetNames), fakePick(rng, fakeStreetSuffixes))
+}
+
+// processFakeEmailFunction generates a unique looking email address: fake.email([domain])
+func processFakeEmailFunction(ip *Interpolator, args []string) (string, error) {
+	rng := ip.random()
+	domain := fakePick(rng, fakeEmailDomains)
+	if len(args) > 0 && args[0] != "" {
+		domain = args[0]
+	}
+	return fakeUsername(rng) + "@" + domain, nil
+}
+
+// processFakePhoneFunction generates a phone number in E.164 format: fake.phone([country code]).
+// The country code defaults to 1.
+func processFakePhoneFunction(ip *Interpolator, args []string) (string, error) {
+	rng := ip.random()
+	countryCode := "1"
+	if len(args) > 0 && args[0] != "" {
+		countryCode = strings.TrimPrefix(args[0], "+")
+		if _, err := strconv.Atoi(countryCode); err != nil {
+			return "", fmt.Errorf("invalid country code: %s", args[0])
+		}
+	}
+	// Avoid a leading zero in the subscriber number
+	return "+" + countryCode + strconv.Itoa(2+rng.Intn(8)) + fakeDigits(rng, 9), nil
+}
+
+// processFakeAddressFunction generates a single line postal address
+func processFakeAddressFunction(ip *Interpolator, args []string) (string, error) {
+	rng := ip.random()
+	return fmt.Sprintf("%s, %s %s, %s", fakeStreet(rng), fakePick(rng, fakeCities), fakeDigits(rng, 5), fakePick(rng, fakeCountries)), nil
+}
+
+// ibanLengths holds the BBAN length of supported IBAN countries
+var ibanLengths = map[string]int{
+	"DE": 18,
+	"GB": 18,
+	"FR": 23,
+	"NL": 14,
+	"ES": 20,
+	"IT": 23,
+	"BE": 12,
+	"CH": 17,
+}
+
+// processFakeIbanFunction generates an IBAN with valid check digits: fake.iban([country]).
+// The country defaults to DE. The bank part is numeric, which is accepted by validators
+// that only verify the checksum but may not be a real bank.
+func processFakeIbanFunction(ip *Interpolator, args []string) (string, error) {
+	rng := ip.random()
+	country := "DE"
+	if len(args) > 0 && args[0] != "" {
+		country = strings.ToUpper(args[0])
+	}
+
+	length, ok := ibanLengths[country]
+	if !ok {
+		return "", fmt.Errorf("unsupported IBAN country: %s", country)
+	}
+
+	bban := fakeDigits(rng, length)
+	if country == "GB" || country == "NL" {
+		// These countries start the BBAN with a four letter bank code
+		bban = "ABCD" + bban[4:]
+	}
+
+	return country + ibanCheckDigits(country, bban) + bban, nil
+}
+
+// ibanCheckDigits computes the ISO 13616 check digits for a country code and BBAN
+func ibanCheckDigits(country, bban string) string {
+	var numeric strings.Builder
+	for _, r := range bban + country + "00" {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+
+	n, _ := new(big.Int).SetString(numeric.String(), 10)
+	remainder := new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("%02d", 98-remainder)
+}
+
+// processFakeLoremFunction generates lorem ipsum text: fake.lorem([words]). Defaults to 10 words.
+func processFakeLoremFunction(ip *Interpolator, args []string) (string, error) {
+	rng := ip.random()
+	count := 10
+	if len(args) > 0 && args[0] != "" {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed <= 0 {
+			return "", fmt.Errorf("invalid word count: %s", args[0])
+		}
+		count = parsed
+	}
+
+	words := make([]string, count)
+	for i := range words {
+		words[i] = fakePick(rng, fakeLoremWords)
+	}
+	return strings.Join(words, " "), nil
+}
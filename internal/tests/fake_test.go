@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFakeFunctions(t *testing.T) {
+	tests := []struct {
+		input   string
+		pattern string
+	}{
+		{"${fake.firstName()}", `^\pL+$`},
+		{"${fake.name()}", `^\pL+ \pL+$`},
+		{"${fake.email()}", `^[a-z.]+[0-9]{4}@[a-z.]+$`},
+		{"${fake.email(acme.test)}", `^[a-z.]+[0-9]{4}@acme\.test$`},
+		{"${fake.phone()}", `^\+1[2-9][0-9]{9}$`},
+		{"${fake.phone(+44)}", `^\+44[2-9][0-9]{9}$`},
+		{"${fake.address()}", `^[0-9]+ \pL+ \pL+, \pL+ [0-9]{5}, [\pL ]+$`},
+		{"${fake.zipCode()}", `^[0-9]{5}$`},
+		{"${fake.company()}", `^\pL+ \pL+$`},
+		{"${fake.iban()}", `^DE[0-9]{20}$`},
+		{"${fake.iban(gb)}", `^GB[0-9]{2}ABCD[0-9]{14}$`},
+	}
+
+	for _, tt := range tests {
+		got, err := InterpolateVariables(tt.input, nil)
+		if err != nil {
+			t.Errorf("InterpolateVariables(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		if !regexp.MustCompile(tt.pattern).MatchString(got) {
+			t.Errorf("InterpolateVariables(%q) = %q, want match for %s", tt.input, got, tt.pattern)
+		}
+	}
+
+	lorem, err := InterpolateVariables("${fake.lorem(5)}", nil)
+	if err != nil {
+		t.Fatalf("InterpolateVariables returned an error: %v", err)
+	}
+	if words := strings.Fields(lorem); len(words) != 5 {
+		t.Errorf("fake.lorem(5) = %q, want 5 words", lorem)
+	}
+}
+
+func TestFakeIbanChecksum(t *testing.T) {
+	for _, country := range []string{"DE", "GB", "FR", "NL"} {
+		iban, err := processFakeIbanFunction(nil, []string{country})
+		if err != nil {
+			t.Fatalf("fake.iban(%s) returned an error: %v", country, err)
+		}
+
+		// Move the first four characters to the end and convert letters to numbers;
+		// a valid IBAN is then congruent to 1 mod 97
+		rearranged := iban[4:] + iban[:4]
+		var numeric strings.Builder
+		for _, r := range rearranged {
+			if r >= 'A' && r <= 'Z' {
+				numeric.WriteString(strconv.Itoa(int(r-'A') + 10))
+			} else {
+				numeric.WriteRune(r)
+			}
+		}
+		n, _ := new(big.Int).SetString(numeric.String(), 10)
+		if new(big.Int).Mod(n, big.NewInt(97)).Int64() != 1 {
+			t.Errorf("fake.iban(%s) = %q has invalid check digits", country, iban)
+		}
+	}
+}
+
+func TestSeedRandom_ReproducibleFakeData(t *testing.T) {
+	input := "${fake.email()} ${fake.name()} ${random(8)} ${randomInt(1, 1000)}"
+
+	SeedRandom(42)
+	first, err := InterpolateVariables(input, nil)
+	if err != nil {
+		t.Fatalf("InterpolateVariables returned an error: %v", err)
+	}
+
+	SeedRandom(42)
+	second, err := InterpolateVariables(input, nil)
+	if err != nil {
+		t.Fatalf("InterpolateVariables returned an error: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("seeded runs generated different data: %q and %q", first, second)
+	}
+}
@@ -0,0 +1,614 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TemplateFunction implements a function that can be called from a template, e.g. ${upper(abc)}.
+// Arguments are passed already evaluated.
+type TemplateFunction func(ip *Interpolator, args []string) (string, error)
+
+// templateFunctions holds the functions available in templates, keyed by name
+var templateFunctions = map[string]TemplateFunction{
+	"random": func(ip *Interpolator, args []string) (string, error) {
+		return processRandomFunction(ip.random(), args), nil
+	},
+	"timestamp": func(ip *Interpolator, args []string) (string, error) {
+		return processTimestampFunction(args), nil
+	},
+	"now": func(ip *Interpolator, args []string) (string, error) {
+		return processNowFunction(), nil
+	},
+	"uuid": func(ip *Interpolator, args []string) (string, error) {
+		return processUUIDFunction(ip.random()), nil
+	},
+	"base64Encode": processBase64EncodeFunction,
+	"base64Decode": processBase64DecodeFunction,
+	"urlEncode":    processURLEncodeFunction,
+	"sha256":       processHashFunction(sha256.New),
+	"md5":          processHashFunction(md5.New),
+	"hmac":         processHmacFunction,
+	"date":         processDateFunction,
+	"randomInt":    processRandomIntFunction,
+	"randomChoice": processRandomChoiceFunction,
+	"upper":        processUpperFunction,
+	"lower":        processLowerFunction,
+	"jsonEscape":   processJSONEscapeFunction,
+	"file":         processFileFunction,
+}
+
+// funcCall is a parsed function call
+type funcCall struct {
+	name string
+	args []funcArg
+}
+
+// funcArg is a parsed function argument: text mixed with references, e.g. prefix-${id},
+// or a single nested call
+type funcArg struct {
+	parts []argPart
+}
+
+// argPart is either literal text or a reference
+type argPart struct {
+	text string
+	ref  *templateRef
+}
+
+// refKind is the kind of a ${...} reference that is not a function call
+type refKind int
+
+const (
+	refVariable refKind = iota
+	refEnv
+	refProvider
+)
+
+// templateRef is a parsed ${...} reference: a function call, an environment variable, a
+// secret provider reference or a variable
+type templateRef struct {
+	// call is set for function calls
+	call *funcCall
+	kind refKind
+	// name is the variable, environment variable or provider name
+	name string
+	// reference is the reference passed to a secret provider
+	reference string
+	// fallback is the default value given with ":-", if hasFallback is set
+	fallback    string
+	hasFallback bool
+}
+
+// String returns the name of the reference as reported in an UndefinedVariableError: the
+// variable name, "env:NAME", "provider:reference" or the function name followed by "()"
+func (ref *templateRef) String() string {
+	switch {
+	case ref.call != nil:
+		return ref.call.name + "()"
+	case ref.kind == refEnv:
+		return "env:" + ref.name
+	case ref.kind == refProvider:
+		return ref.name + ":" + ref.reference
+	default:
+		return ref.name
+	}
+}
+
+// Anchored forms of the reference patterns, used to parse a reference at a position
+var (
+	envVarRefAt   = regexp.MustCompile(`^` + envVarPattern.String())
+	providerRefAt = regexp.MustCompile(`^` + providerRefPattern.String())
+	variableRefAt = regexp.MustCompile(`^` + variableRefPattern.String())
+	funcCallAt    = regexp.MustCompile(`^` + funcCallPattern.String())
+)
+
+// callFunction evaluates the arguments of a call and invokes the function. unresolved is
+// the name of the function when it does not exist, or of the first reference in the
+// arguments that could not be resolved, in which case the call is left unchanged.
+func (ip *Interpolator) callFunction(call *funcCall, variables map[string]Variable) (string, string, error) {
+	fn, exists := templateFunctions[call.name]
+	if !exists {
+		fn, exists = fakeFunctions[call.name]
+	}
+	if !exists {
+		return "", call.name + "()", nil
+	}
+
+	args := make([]string, len(call.args))
+	for i, arg := range call.args {
+		value, unresolved, err := ip.evaluateArg(arg, variables)
+		if err != nil || unresolved != "" {
+			return "", unresolved, err
+		}
+		args[i] = value
+	}
+
+	value, err := fn(ip, args)
+	if err != nil {
+		return "", "", fmt.Errorf("error calling function %s: %w", call.name, err)
+	}
+
+	return value, "", nil
+}
+
+// evaluateArg returns the value of a function argument. Referenced values are passed to
+// the function as they are, whatever characters they contain.
+func (ip *Interpolator) evaluateArg(arg funcArg, variables map[string]Variable) (string, string, error) {
+	var sb strings.Builder
+	for _, part := range arg.parts {
+		if part.ref == nil {
+			sb.WriteString(part.text)
+			continue
+		}
+
+		value, unresolved, err := ip.evaluateRef(part.ref, variables)
+		if err != nil || unresolved != "" {
+			return "", unresolved, err
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), "", nil
+}
+
+// malformedRef returns the name to report for text starting with "${" that is not a valid
+// reference: the function name followed by "()" for a malformed call, or the text up to
+// the closing brace. It returns an empty string when there is no closing brace.
+func malformedRef(s string) string {
+	if m := funcCallAt.FindStringSubmatch(s); m != nil {
+		return m[1] + "()"
+	}
+	if end := strings.IndexByte(s, '}'); end >= 0 {
+		return s[:end+1]
+	}
+	return ""
+}
+
+// funcParser parses templates: ${...} references and function calls of the form
+// name(arg, "quoted arg", nested(arg), ${nested()}, ${variable})
+type funcParser struct {
+	input string
+	pos   int
+}
+
+func (p *funcParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *funcParser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *funcParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func isIdentChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && ((c >= '0' && c <= '9') || c == '.')
+}
+
+// parseRef parses a ${...} reference at the current position
+func (p *funcParser) parseRef() (*templateRef, bool) {
+	start := p.pos
+	if !strings.HasPrefix(p.input[start:], "${") {
+		return nil, false
+	}
+
+	p.pos += 2
+	if call, ok := p.parseCall(); ok && p.consume('}') {
+		return &templateRef{call: call}, true
+	}
+
+	rest := p.input[start:]
+	if m := envVarRefAt.FindStringSubmatchIndex(rest); m != nil {
+		p.pos = start + m[1]
+		return &templateRef{kind: refEnv, name: rest[m[2]:m[3]], fallback: submatch(rest, m, 2), hasFallback: m[4] >= 0}, true
+	}
+	if m := variableRefAt.FindStringSubmatchIndex(rest); m != nil {
+		p.pos = start + m[1]
+		return &templateRef{kind: refVariable, name: rest[m[2]:m[3]], fallback: submatch(rest, m, 2), hasFallback: m[4] >= 0}, true
+	}
+	if m := providerRefAt.FindStringSubmatchIndex(rest); m != nil {
+		p.pos = start + m[1]
+		return &templateRef{kind: refProvider, name: rest[m[2]:m[3]], reference: rest[m[4]:m[5]], fallback: submatch(rest, m, 3), hasFallback: m[6] >= 0}, true
+	}
+
+	p.pos = start
+	return nil, false
+}
+
+// submatch returns the text of group n of a match from FindStringSubmatchIndex, or an
+// empty string if the group did not match
+func submatch(s string, m []int, n int) string {
+	if m[2*n] < 0 {
+		return ""
+	}
+	return s[m[2*n]:m[2*n+1]]
+}
+
+// parseCall parses a function name followed by a parenthesized argument list
+func (p *funcParser) parseCall() (*funcCall, bool) {
+	start := p.pos
+	for p.pos < len(p.input) && isIdentChar(p.input[p.pos], p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start || !p.consume('(') {
+		return nil, false
+	}
+
+	call := &funcCall{name: p.input[start : p.pos-1]}
+
+	p.skipSpaces()
+	if p.consume(')') {
+		return call, true
+	}
+
+	for {
+		arg, ok := p.parseArg()
+		if !ok {
+			return nil, false
+		}
+		call.args = append(call.args, arg)
+
+		p.skipSpaces()
+		if p.consume(',') {
+			continue
+		}
+		if p.consume(')') {
+			return call, true
+		}
+		return nil, false
+	}
+}
+
+// parseArg parses a single argument: a quoted string, a nested call or bare text
+func (p *funcParser) parseArg() (funcArg, bool) {
+	p.skipSpaces()
+
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseQuoted(c)
+	case isIdentChar(c, true):
+		// Try a nested call, falling back to bare text
+		start := p.pos
+		if call, ok := p.parseCall(); ok {
+			return funcArg{parts: []argPart{{ref: &templateRef{call: call}}}}, true
+		}
+		p.pos = start
+	}
+
+	return p.parseBare(), true
+}
+
+// parseQuoted parses a string delimited by quote, supporting backslash escapes and
+// references
+func (p *funcParser) parseQuoted(quote byte) (funcArg, bool) {
+	p.pos++
+	var arg funcArg
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		if ref, ok := p.parseRef(); ok {
+			arg.add(&sb, ref)
+			continue
+		}
+
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.input):
+			sb.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == quote:
+			arg.add(&sb, nil)
+			return arg, true
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return funcArg{}, false
+}
+
+// parseBare reads unquoted text and references up to the next top-level comma or closing
+// parenthesis. Surrounding spaces are trimmed.
+func (p *funcParser) parseBare() funcArg {
+	var arg funcArg
+	var sb strings.Builder
+	depth := 0
+	for p.pos < len(p.input) {
+		if ref, ok := p.parseRef(); ok {
+			arg.add(&sb, ref)
+			continue
+		}
+
+		c := p.input[p.pos]
+		if (c == ',' || c == ')') && depth == 0 || c == '}' {
+			break
+		}
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	arg.add(&sb, nil)
+
+	if n := len(arg.parts); n > 0 && arg.parts[n-1].ref == nil {
+		arg.parts[n-1].text = strings.TrimRightFunc(arg.parts[n-1].text, unicode.IsSpace)
+	}
+	return arg
+}
+
+// add appends the text in sb, if any, followed by ref, if not nil, and resets sb
+func (a *funcArg) add(sb *strings.Builder, ref *templateRef) {
+	if sb.Len() > 0 {
+		a.parts = append(a.parts, argPart{text: sb.String()})
+		sb.Reset()
+	}
+	if ref != nil {
+		a.parts = append(a.parts, argPart{ref: ref})
+	}
+}
+
+// requireArgs returns an error unless at least n arguments were passed
+func requireArgs(args []string, n int) error {
+	if len(args) < n {
+		return fmt.Errorf("expected at least %d argument(s), got %d", n, len(args))
+	}
+	return nil
+}
+
+// processBase64EncodeFunction base64 encodes its argument
+func processBase64EncodeFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// processBase64DecodeFunction decodes a base64 encoded argument
+func processBase64DecodeFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(args[0])
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// processURLEncodeFunction escapes its argument for use in a URL query
+func processURLEncodeFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	return url.QueryEscape(args[0]), nil
+}
+
+// processHashFunction returns a function that hex encodes the hash of its argument
+func processHashFunction(newHash func() hash.Hash) TemplateFunction {
+	return func(ip *Interpolator, args []string) (string, error) {
+		if err := requireArgs(args, 1); err != nil {
+			return "", err
+		}
+		h := newHash()
+		h.Write([]byte(args[0]))
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+}
+
+// processHmacFunction hex encodes the HMAC of a message: hmac(key, message[, algorithm]).
+// Supported algorithms are sha256 (default), sha1, sha512 and md5.
+func processHmacFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 2); err != nil {
+		return "", err
+	}
+
+	algorithm := "sha256"
+	if len(args) > 2 && args[2] != "" {
+		algorithm = strings.ToLower(args[2])
+	}
+
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "sha256":
+		newHash = sha256.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha512":
+		newHash = sha512.New
+	case "md5":
+		newHash = md5.New
+	default:
+		return "", fmt.Errorf("unsupported hmac algorithm: %s", algorithm)
+	}
+
+	mac := hmac.New(newHash, []byte(args[0]))
+	mac.Write([]byte(args[1]))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// processDateFunction formats the current time shifted by an offset: date(expression[, layout]).
+// The expression is "now" optionally followed by offsets such as "now + 1h" or "now - 7d".
+// The layout uses Go's time formatting syntax and defaults to RFC 3339.
+func processDateFunction(ip *Interpolator, args []string) (string, error) {
+	expression := "now"
+	if len(args) > 0 && args[0] != "" {
+		expression = args[0]
+	}
+
+	layout := time.RFC3339
+	if len(args) > 1 && args[1] != "" {
+		layout = args[1]
+	}
+
+	t, err := evaluateDateExpression(expression, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	return t.Format(layout), nil
+}
+
+// evaluateDateExpression applies the offsets in an expression like "now + 1h - 30m" to now
+func evaluateDateExpression(expression string, now time.Time) (time.Time, error) {
+	expr := strings.ReplaceAll(strings.TrimSpace(expression), " ", "")
+	if !strings.HasPrefix(expr, "now") {
+		return time.Time{}, fmt.Errorf("invalid date expression %q: must start with now", expression)
+	}
+	expr = strings.TrimPrefix(expr, "now")
+
+	result := now
+	for expr != "" {
+		sign := expr[0]
+		if sign != '+' && sign != '-' {
+			return time.Time{}, fmt.Errorf("invalid date expression %q: expected + or -", expression)
+		}
+
+		// Read up to the next operator
+		end := 1
+		for end < len(expr) && expr[end] != '+' && expr[end] != '-' {
+			end++
+		}
+
+		offset, err := parseDateOffset(expr[1:end])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date expression %q: %w", expression, err)
+		}
+		if sign == '-' {
+			offset = -offset
+		}
+
+		result = result.Add(offset)
+		expr = expr[end:]
+	}
+
+	return result, nil
+}
+
+// parseDateOffset parses a duration, additionally accepting a "d" suffix for days
+func parseDateOffset(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days: %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// processRandomIntFunction generates a random integer in the inclusive range: randomInt(min, max).
+// The range defaults to 0-100.
+func processRandomIntFunction(ip *Interpolator, args []string) (string, error) {
+	min, max := 0, 100
+
+	var err error
+	if len(args) > 0 && args[0] != "" {
+		if min, err = strconv.Atoi(args[0]); err != nil {
+			return "", fmt.Errorf("invalid minimum: %s", args[0])
+		}
+	}
+	if len(args) > 1 && args[1] != "" {
+		if max, err = strconv.Atoi(args[1]); err != nil {
+			return "", fmt.Errorf("invalid maximum: %s", args[1])
+		}
+	}
+	if max < min {
+		return "", fmt.Errorf("maximum %d is less than minimum %d", max, min)
+	}
+	// The number of values in the range must fit in an int
+	if uint(max)-uint(min) >= math.MaxInt {
+		return "", fmt.Errorf("range %d to %d is too large", min, max)
+	}
+
+	return strconv.Itoa(min + ip.random().Intn(max-min+1)), nil
+}
+
+// processRandomChoiceFunction returns one of its arguments at random
+func processRandomChoiceFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	return args[ip.random().Intn(len(args))], nil
+}
+
+// processUpperFunction converts its argument to upper case
+func processUpperFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(args[0]), nil
+}
+
+// processLowerFunction converts its argument to lower case
+func processLowerFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	return strings.ToLower(args[0]), nil
+}
+
+// processJSONEscapeFunction escapes its argument for use inside a JSON string literal
+func processJSONEscapeFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(args[0])
+	if err != nil {
+		return "", err
+	}
+	// Strip the surrounding quotes
+	return string(encoded[1 : len(encoded)-1]), nil
+}
+
+// processFileFunction returns the contents of a file without trailing whitespace.
+// Relative paths are resolved against the directory of the test definition.
+func processFileFunction(ip *Interpolator, args []string) (string, error) {
+	if err := requireArgs(args, 1); err != nil {
+		return "", err
+	}
+
+	path := args[0]
+	if !filepath.IsAbs(path) && ip.BaseDir != "" {
+		path = filepath.Join(ip.BaseDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRightFunc(string(data), unicode.IsSpace), nil
+}
@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestInterpolateVariables_Functions(t *testing.T) {
+	variables := map[string]Variable{
+		"name":   {Type: "string", Value: "John"},
+		"secret": {Type: "string", Value: "key"},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"${base64Encode(user:pass)}", "dXNlcjpwYXNz"},
+		{"${base64Decode(dXNlcjpwYXNz)}", "user:pass"},
+		{"${urlEncode(\"a b&c=d\")}", "a+b%26c%3Dd"},
+		{"${sha256(abc)}", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"${md5(abc)}", "900150983cd24fb0d6963f7d28e17f72"},
+		{"${hmac(${secret}, message)}", "6e9ef29b75fffc5b7abae527d58fdadb2fe42e7219011976917343065f58ed4a"},
+		{"${upper(${name})}", "JOHN"},
+		{"${lower(ABC)}", "abc"},
+		{"${jsonEscape('say \"hi\"')}", `say \"hi\"`},
+		{"${randomChoice(only)}", "only"},
+		{"${randomInt(7, 7)}", "7"},
+		// Quoted arguments may contain parentheses and commas
+		{"${upper(\"f(x), g(y)\")}", "F(X), G(Y)"},
+		// Nested calls, with or without ${}
+		{"${base64Decode(base64Encode(nested))}", "nested"},
+		{"${upper(${base64Decode(aGk=)})}", "HI"},
+		// Unknown functions are left unchanged
+		{"${unknownFunc(1)}", "${unknownFunc(1)}"},
+		{"prefix-${lower(A)}-${lower(B)}-suffix", "prefix-a-b-suffix"},
+	}
+
+	for _, tt := range tests {
+		got, err := InterpolateVariables(tt.input, variables)
+		if err != nil {
+			t.Errorf("InterpolateVariables(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("InterpolateVariables(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolateVariables_FunctionErrors(t *testing.T) {
+	inputs := []string{
+		"${base64Decode(not-base64!)}",
+		"${randomInt(10, 1)}",
+		"${randomInt(-9223372036854775808, 9223372036854775807)}",
+		"${randomInt(0, 9223372036854775807)}",
+		"${date(tomorrow)}",
+		"${hmac(key, message, sha3)}",
+		"${file(does-not-exist.txt)}",
+	}
+
+	for _, input := range inputs {
+		if _, err := InterpolateVariables(input, nil); err == nil {
+			t.Errorf("InterpolateVariables(%q) expected an error", input)
+		}
+	}
+}
+
+func TestInterpolateVariables_RandomInt(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		got, err := InterpolateVariables("${randomInt(1, 3)}", nil)
+		if err != nil {
+			t.Fatalf("InterpolateVariables returned an error: %v", err)
+		}
+		n, err := strconv.Atoi(got)
+		if err != nil || n < 1 || n > 3 {
+			t.Fatalf("randomInt(1, 3) = %q, want a number between 1 and 3", got)
+		}
+	}
+}
+
+func TestEvaluateDateExpression(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"now", now},
+		{"now + 1h", now.Add(time.Hour)},
+		{"now - 30m", now.Add(-30 * time.Minute)},
+		{"now + 2d - 1h", now.Add(47 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		got, err := evaluateDateExpression(tt.expression, now)
+		if err != nil {
+			t.Errorf("evaluateDateExpression(%q) returned an error: %v", tt.expression, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("evaluateDateExpression(%q) = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+
+	got, err := InterpolateVariables("${date(now + 24h, 2006-01-02)}", nil)
+	if err != nil {
+		t.Fatalf("InterpolateVariables returned an error: %v", err)
+	}
+	if want := time.Now().Add(24 * time.Hour).Format("2006-01-02"); got != want {
+		t.Errorf("date(now + 24h) = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolator_FileFunction(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "payload.json"), []byte("{\"id\": 1}\n"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	interpolator := &Interpolator{BaseDir: dir}
+	got, err := interpolator.InterpolateVariables("${file(payload.json)}", nil)
+	if err != nil {
+		t.Fatalf("InterpolateVariables returned an error: %v", err)
+	}
+	if got != `{"id": 1}` {
+		t.Errorf("file(payload.json) = %q, want %q", got, `{"id": 1}`)
+	}
+}
+
+func TestInterpolator_ValuesAreNotEvaluated(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("local secret"), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	env := NewEnvironment()
+	env.Set("HOSTILE", "${secret}")
+
+	interpolator := &Interpolator{BaseDir: dir, Env: env}
+	body := `{"note":"a, b) and }","n":1}`
+	variables := map[string]Variable{
+		"exported": {Value: "${file(secret.txt)}"},
+		"provider": {Value: "${file:secret.txt}"},
+		"body":     {Value: body},
+		"password": {Value: "p)ss"},
+		"message":  {Value: "a,b"},
+		"secret":   {Value: "leaked"},
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte("a,b"))
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// Values that look like templates are used as they are
+		{"${exported}", "${file(secret.txt)}"},
+		{"${provider}", "${file:secret.txt}"},
+		{"${env:HOSTILE}", "${secret}"},
+		{"${upper(${exported})}", "${FILE(SECRET.TXT)}"},
+		// Values are passed to functions as a single argument
+		{"${sha256(${body})}", hex.EncodeToString(sum[:])},
+		{"${upper(${password})}", "P)SS"},
+		{"${hmac(key, ${message})}", hex.EncodeToString(mac.Sum(nil))},
+		{"${upper(\"x ${password}\")}", "X P)SS"},
+		{"${upper(id-${password} )}", "ID-P)SS"},
+	}
+
+	for _, tt := range tests {
+		got, err := interpolator.InterpolateVariables(tt.input, variables)
+		if err != nil {
+			t.Errorf("InterpolateVariables(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("InterpolateVariables(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolator_UnresolvedFunctionArguments(t *testing.T) {
+	// Calls with arguments that cannot be resolved are left unchanged, so strict mode
+	// reports the missing variable
+	got, unresolved, err := defaultInterpolator.interpolate("${sha256(${missing})}", nil)
+	if err != nil {
+		t.Fatalf("interpolate returned an error: %v", err)
+	}
+	if got != "${sha256(${missing})}" {
+		t.Errorf("interpolate() = %q, want the call unchanged", got)
+	}
+	if unresolved != "missing" {
+		t.Errorf("interpolate() reported %q as unresolved, want missing", unresolved)
+	}
+}
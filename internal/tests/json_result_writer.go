@@ -22,10 +22,17 @@ func NewJSONResultWriter(outputPath string) *JSONResultWriter {
 
 // JSONResult is a serializable representation of test results
 type JSONResult struct {
+	Run             JSONRunInfo          `json:"run"`
 	TestDefinitions []JSONTestDefinition `json:"testDefinitions"`
 	Summary         JSONSummary          `json:"summary"`
 }
 
+type JSONRunInfo struct {
+	Seed        int64  `json:"seed"`
+	Environment string `json:"environment,omitempty"`
+	Cancelled   string `json:"cancelled,omitempty"`
+}
+
 type JSONTestDefinition struct {
 	Name   string          `json:"name"`
 	Path   string          `json:"path"`
@@ -40,6 +47,9 @@ type JSONTestSuite struct {
 type JSONTestCase struct {
 	Name           string   `json:"name"`
 	Passed         bool     `json:"passed"`
+	Errored        bool     `json:"errored,omitempty"`
+	TimedOut       bool     `json:"timedOut,omitempty"`
+	Cancelled      bool     `json:"cancelled,omitempty"`
 	Timing         float64  `json:"timingMs"`
 	FailureReasons []string `json:"failureReasons,omitempty"`
 }
@@ -53,8 +63,13 @@ type JSONSummary struct {
 	TotalTimeMs          float64 `json:"totalTimeMs"`
 }
 
-func (w *JSONResultWriter) Write(results map[string]TestDefinitionExecResult) {
+func (w *JSONResultWriter) Write(results map[string]TestDefinitionExecResult, info RunInfo) {
 	jsonResult := JSONResult{
+		Run: JSONRunInfo{
+			Seed:        info.Seed,
+			Environment: info.Environment,
+			Cancelled:   info.Cancelled,
+		},
 		TestDefinitions: make([]JSONTestDefinition, 0, len(results)),
 		Summary:         JSONSummary{},
 	}
@@ -95,6 +110,9 @@ func (w *JSONResultWriter) Write(results map[string]TestDefinitionExecResult) {
 				jsonCase := JSONTestCase{
 					Name:           caseName,
 					Passed:         caseResult.Passed,
+					Errored:        caseResult.Errored,
+					TimedOut:       caseResult.TimedOut,
+					Cancelled:      caseResult.Cancelled,
 					Timing:         caseResult.Timing,
 					FailureReasons: caseResult.FailureReasons,
 				}
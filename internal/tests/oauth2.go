@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+// Supported OAuth2 grants
+const (
+	OAuth2GrantClientCredentials = "client_credentials"
+	OAuth2GrantPassword          = "password"
+)
+
+// tokenExpiryMargin is how long before its expiry a cached token is refreshed, so it does
+// not expire while a request is in flight
+const tokenExpiryMargin = 10 * time.Second
+
+// oauth2Token is an access token returned by a token endpoint
+type oauth2Token struct {
+	AccessToken  string
+	RefreshToken string
+	// Expiry is when the token expires. The zero value means it does not expire.
+	Expiry time.Time
+}
+
+// valid reports whether the token can still be used at now
+func (t *oauth2Token) valid(now time.Time) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || now.Add(tokenExpiryMargin).Before(t.Expiry)
+}
+
+// TokenCache caches OAuth2 access tokens for a run, keyed by token endpoint and credentials.
+// It is safe for concurrent use; concurrent requests for the same token wait for a single
+// token request.
+type TokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*tokenCacheEntry
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// tokenCacheEntry holds the token for one set of credentials. Its mutex is held while the
+// token is fetched.
+type tokenCacheEntry struct {
+	mu    sync.Mutex
+	token *oauth2Token
+}
+
+// NewTokenCache creates an empty TokenCache
+func NewTokenCache() *TokenCache {
+	return &TokenCache{
+		entries: make(map[string]*tokenCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// defaultTokenCache is used by suites that are not given a TokenCache by the runner
+var defaultTokenCache = NewTokenCache()
+
+// entry returns the cache entry for the credentials in auth
+func (c *TokenCache) entry(auth *Auth) *tokenCacheEntry {
+	key := strings.Join([]string{
+		auth.TokenURL, auth.grant(), auth.ClientID, auth.ClientSecret,
+		auth.Username, auth.Password, strings.Join(auth.Scopes, " "),
+	}, "\x00")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		entry = &tokenCacheEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// Token returns a valid access token for the OAuth2 configuration in auth. A cached token
+// is returned while it is valid. Expired tokens are refreshed with their refresh token if
+// they have one, or replaced by requesting a new token.
+func (c *TokenCache) Token(ctx context.Context, auth *Auth, client easyreq.HttpClient) (string, error) {
+	entry := c.entry(auth)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.token.valid(c.now()) {
+		return entry.token.AccessToken, nil
+	}
+
+	var token *oauth2Token
+	var err error
+	if entry.token != nil && entry.token.RefreshToken != "" {
+		token, err = c.requestToken(ctx, auth, client, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {entry.token.RefreshToken},
+		})
+	}
+	if token == nil || err != nil {
+		token, err = c.requestToken(ctx, auth, client, auth.grantForm())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	entry.token = token
+	return token.AccessToken, nil
+}
+
+// Invalidate removes token from the cache, e.g. after the server rejected it. The cached
+// token is only removed if it is still the same token, so a token refreshed in the meantime
+// by a concurrent request is kept.
+func (c *TokenCache) Invalidate(auth *Auth, token string) {
+	entry := c.entry(auth)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.token != nil && entry.token.AccessToken == token {
+		entry.token = nil
+	}
+}
+
+// requestToken sends a token request with form to the token endpoint
+func (c *TokenCache) requestToken(ctx context.Context, auth *Auth, client easyreq.HttpClient, form url.Values) (*oauth2Token, error) {
+	if len(auth.Scopes) > 0 && form.Get("grant_type") != "refresh_token" {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+
+	headers := map[string]interface{}{
+		"Accept": "application/json",
+	}
+
+	// Client credentials are sent with basic auth unless the server expects them in the body
+	if strings.EqualFold(auth.ClientAuth, "body") {
+		form.Set("client_id", auth.ClientID)
+		if auth.ClientSecret != "" {
+			form.Set("client_secret", auth.ClientSecret)
+		}
+	} else {
+		credentials := url.QueryEscape(auth.ClientID) + ":" + url.QueryEscape(auth.ClientSecret)
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+
+	resp, err := client.Post(ctx, auth.TokenURL, form, easyreq.RequestParams{Headers: headers})
+	if err != nil {
+		return nil, fmt.Errorf("error requesting OAuth2 token: %w", err)
+	}
+
+	if resp.Status < 200 || resp.Status > 299 {
+		return nil, fmt.Errorf("OAuth2 token request failed with status %d: %s", resp.Status, strings.TrimSpace(string(resp.Body)))
+	}
+
+	var body struct {
+		AccessToken  string      `json:"access_token"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("error parsing OAuth2 token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("OAuth2 token response has no access_token")
+	}
+
+	token := &oauth2Token{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+	}
+	if body.ExpiresIn != "" {
+		seconds, err := strconv.ParseFloat(string(body.ExpiresIn), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_in in OAuth2 token response: %s", body.ExpiresIn)
+		}
+		token.Expiry = c.now().Add(time.Duration(seconds * float64(time.Second)))
+	}
+
+	return token, nil
+}
+
+// grant returns the OAuth2 grant, defaulting to client_credentials
+func (a *Auth) grant() string {
+	if a.Grant == "" {
+		return OAuth2GrantClientCredentials
+	}
+	return strings.ToLower(a.Grant)
+}
+
+// grantForm returns the token request form for the configured grant
+func (a *Auth) grantForm() url.Values {
+	form := url.Values{"grant_type": {a.grant()}}
+	if a.grant() == OAuth2GrantPassword {
+		form.Set("username", a.Username)
+		form.Set("password", a.Password)
+	}
+	return form
+}
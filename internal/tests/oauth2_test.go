@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+// stubTokenServer is a local OAuth2 token endpoint and protected API. It issues numbered
+// tokens and the API accepts only the most recently issued one.
+type stubTokenServer struct {
+	*httptest.Server
+	issued    atomic.Int32
+	refreshed atomic.Int32
+	expiresIn int
+	mu        sync.Mutex
+	current   string
+	lastForm  map[string]string
+}
+
+func newStubTokenServer(t *testing.T, expiresIn int) *stubTokenServer {
+	s := &stubTokenServer{expiresIn: expiresIn}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			s.token(w, r)
+		case "/api":
+			s.mu.Lock()
+			current := s.current
+			s.mu.Unlock()
+			if r.Header.Get("Authorization") != "Bearer "+current {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stubTokenServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != "client" || clientSecret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	form := make(map[string]string)
+	for k := range r.PostForm {
+		form[k] = r.PostForm.Get(k)
+	}
+	if form["grant_type"] == "refresh_token" {
+		s.refreshed.Add(1)
+	}
+
+	n := s.issued.Add(1)
+	token := fmt.Sprintf("token-%d", n)
+
+	s.mu.Lock()
+	s.current = token
+	s.lastForm = form
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  token,
+		"token_type":    "Bearer",
+		"expires_in":    s.expiresIn,
+		"refresh_token": fmt.Sprintf("refresh-%d", n),
+	})
+}
+
+// revoke makes the API reject every issued token until a new one is requested
+func (s *stubTokenServer) revoke() {
+	s.mu.Lock()
+	s.current = "revoked"
+	s.mu.Unlock()
+}
+
+func oauth2TestAuth(server *stubTokenServer) *Auth {
+	return &Auth{
+		Type:         "oauth2",
+		TokenURL:     server.URL + "/token",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}
+}
+
+func TestExecCase_OAuth2(t *testing.T) {
+	server := newStubTokenServer(t, 3600)
+	client := easyreq.New(easyreq.NewOptions())
+
+	suite := &TestSuite{
+		Auth:    oauth2TestAuth(server),
+		Secrets: NewSecretStore(),
+		Tokens:  NewTokenCache(),
+	}
+	testCase := &TestCase{
+		Title: "protected",
+		Request: Request{
+			Method:     "GET",
+			URL:        server.URL + "/api",
+			Assertions: map[string]interface{}{"status": 200},
+		},
+	}
+
+	run := func() {
+		t.Helper()
+		result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Passed {
+			t.Fatalf("expected test case to pass: %v", result.FailureReasons)
+		}
+	}
+
+	// The token is fetched once and reused
+	run()
+	run()
+	if got := server.issued.Load(); got != 1 {
+		t.Errorf("expected 1 token request, got %d", got)
+	}
+	if server.lastForm["grant_type"] != "client_credentials" || server.lastForm["scope"] != "read write" {
+		t.Errorf("unexpected token request form: %v", server.lastForm)
+	}
+	if got := suite.Secrets.Redact("token-1"); got != RedactedValue {
+		t.Errorf("expected access token to be redacted, got %q", got)
+	}
+
+	// A rejected token is replaced and the request retried
+	server.revoke()
+	run()
+	if got := server.issued.Load(); got != 2 {
+		t.Errorf("expected a new token after a 401, got %d token requests", got)
+	}
+}
+
+func TestTokenCache_Expiry(t *testing.T) {
+	server := newStubTokenServer(t, 60)
+	client := easyreq.New(easyreq.NewOptions())
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewTokenCache()
+	cache.now = func() time.Time { return now }
+
+	auth := oauth2TestAuth(server)
+	auth.Grant = "password"
+	auth.Username = "alice"
+	auth.Password = "pw"
+
+	token, err := cache.Token(context.Background(), auth, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "token-1" || server.lastForm["username"] != "alice" || server.lastForm["password"] != "pw" {
+		t.Errorf("unexpected token %q for password grant form %v", token, server.lastForm)
+	}
+
+	now = now.Add(30 * time.Second)
+	if token, _ := cache.Token(context.Background(), auth, client); token != "token-1" {
+		t.Errorf("expected cached token before expiry, got %q", token)
+	}
+
+	// Close to expiry the token is refreshed with the refresh token
+	now = now.Add(25 * time.Second)
+	token, err = cache.Token(context.Background(), auth, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "token-2" || server.refreshed.Load() != 1 {
+		t.Errorf("expected refreshed token-2, got %q after %d refreshes", token, server.refreshed.Load())
+	}
+}
+
+func TestTokenCache_Concurrent(t *testing.T) {
+	server := newStubTokenServer(t, 3600)
+	client := easyreq.New(easyreq.NewOptions())
+	cache := NewTokenCache()
+	auth := oauth2TestAuth(server)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			copied := *auth
+			if token, err := cache.Token(context.Background(), &copied, client); err != nil || token != "token-1" {
+				t.Errorf("expected token-1, got %q (%v)", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := server.issued.Load(); got != 1 {
+		t.Errorf("expected concurrent requests to share 1 token request, got %d", got)
+	}
+
+	// Different credentials get their own token
+	other := *auth
+	other.Scopes = []string{"admin"}
+	if token, _ := cache.Token(context.Background(), &other, client); token != "token-2" {
+		t.Errorf("expected a separate token for other scopes, got %q", token)
+	}
+}
+
+func TestTokenCache_Error(t *testing.T) {
+	server := newStubTokenServer(t, 3600)
+	auth := oauth2TestAuth(server)
+	auth.ClientSecret = "wrong"
+
+	if _, err := NewTokenCache().Token(context.Background(), auth, easyreq.New(easyreq.NewOptions())); err == nil {
+		t.Error("expected error for rejected client credentials")
+	}
+}
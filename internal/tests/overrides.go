@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// supportedVariableTypes lists the types accepted for variables
+var supportedVariableTypes = []string{"string", "int", "float", "bool"}
+
+// ParseVariableOverride parses a command-line variable override in the form key=value or
+// key:type=value, e.g. "base_url=https://ci.example.com" or "retries:int=3"
+func ParseVariableOverride(override string) (string, Variable, error) {
+	parts := strings.SplitN(override, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", Variable{}, fmt.Errorf("invalid variable %q, expected key=value or key:type=value", override)
+	}
+
+	name := strings.TrimSpace(parts[0])
+	variable := Variable{Type: "string", Value: parts[1]}
+
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		variable.Type = strings.TrimSpace(name[idx+1:])
+		name = strings.TrimSpace(name[:idx])
+	}
+
+	if name == "" {
+		return "", Variable{}, fmt.Errorf("invalid variable %q, missing name", override)
+	}
+
+	if err := validateVariableType(name, variable); err != nil {
+		return "", Variable{}, err
+	}
+
+	return name, variable, nil
+}
+
+// LoadVariablesFile loads variables from a YAML or JSON file. Each entry is either a plain
+// value, whose type is inferred, or an object with type and value fields:
+//
+//	base_url: https://ci.example.com
+//	retries: 3
+//	timeout:
+//	  type: float
+//	  value: "1.5"
+//	token:
+//	  value: abc123
+//	  secret: true
+func LoadVariablesFile(path string) (map[string]Variable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading variables file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := unmarshalByExt(data, filepath.Ext(path), &raw); err != nil {
+		return nil, fmt.Errorf("error parsing variables file %s: %w", path, err)
+	}
+
+	variables := make(map[string]Variable, len(raw))
+	for name, value := range raw {
+		variable, err := variableFromValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variable %s in %s: %w", name, path, err)
+		}
+		if err := validateVariableType(name, variable); err != nil {
+			return nil, err
+		}
+		variables[name] = variable
+	}
+
+	return variables, nil
+}
+
+// variableFromValue converts a decoded YAML or JSON value into a Variable
+func variableFromValue(value interface{}) (Variable, error) {
+	switch v := value.(type) {
+	case string:
+		return Variable{Type: "string", Value: v}, nil
+	case int:
+		return Variable{Type: "int", Value: strconv.Itoa(v)}, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt32 {
+			return Variable{Type: "int", Value: strconv.Itoa(int(v))}, nil
+		}
+		return Variable{Type: "float", Value: strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case bool:
+		return Variable{Type: "bool", Value: strconv.FormatBool(v)}, nil
+	case map[string]interface{}:
+		variable := Variable{Type: "string"}
+		if t, ok := v["type"].(string); ok && t != "" {
+			variable.Type = t
+		}
+		if val, ok := v["value"]; ok && val != nil {
+			variable.Value = fmt.Sprintf("%v", val)
+		}
+		if secret, ok := v["secret"].(bool); ok {
+			variable.Secret = secret
+		}
+		return variable, nil
+	default:
+		return Variable{}, fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// validateVariableType checks that a variable has a supported type and a value of that type
+func validateVariableType(name string, variable Variable) error {
+	supported := false
+	for _, t := range supportedVariableTypes {
+		if variable.Type == t {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("invalid type %q for variable %s, expected one of %s",
+			variable.Type, name, strings.Join(supportedVariableTypes, ", "))
+	}
+
+	if _, err := CoerceVariableValue(variable); err != nil {
+		return fmt.Errorf("invalid %s value %q for variable %s", variable.Type, variable.Value, name)
+	}
+
+	return nil
+}
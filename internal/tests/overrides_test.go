@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseVariableOverride(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantName string
+		want     Variable
+		wantErr  bool
+	}{
+		{"base_url=https://ci.example.com", "base_url", Variable{Type: "string", Value: "https://ci.example.com"}, false},
+		{"query=a=b", "query", Variable{Type: "string", Value: "a=b"}, false},
+		{"retries:int=3", "retries", Variable{Type: "int", Value: "3"}, false},
+		{"enabled:bool=true", "enabled", Variable{Type: "bool", Value: "true"}, false},
+		{"empty=", "empty", Variable{Type: "string", Value: ""}, false},
+		{"retries:int=three", "", Variable{}, true},
+		{"retries:uint=3", "", Variable{}, true},
+		{"no_value", "", Variable{}, true},
+		{"=value", "", Variable{}, true},
+	}
+
+	for _, tt := range tests {
+		name, variable, err := ParseVariableOverride(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVariableOverride(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if name != tt.wantName || variable != tt.want {
+			t.Errorf("ParseVariableOverride(%q) = %q, %+v, want %q, %+v", tt.input, name, variable, tt.wantName, tt.want)
+		}
+	}
+}
+
+func TestLoadVariablesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vars.yaml")
+	content := `
+base_url: https://ci.example.com
+retries: 3
+enabled: true
+timeout:
+  type: float
+  value: "1.5"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	variables, err := LoadVariablesFile(path)
+	if err != nil {
+		t.Fatalf("LoadVariablesFile returned an error: %v", err)
+	}
+
+	expected := map[string]Variable{
+		"base_url": {Type: "string", Value: "https://ci.example.com"},
+		"retries":  {Type: "int", Value: "3"},
+		"enabled":  {Type: "bool", Value: "true"},
+		"timeout":  {Type: "float", Value: "1.5"},
+	}
+	for name, want := range expected {
+		if got := variables[name]; got != want {
+			t.Errorf("%s = %+v, want %+v", name, got, want)
+		}
+	}
+
+	jsonPath := filepath.Join(dir, "vars.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"count": 2, "rate": 0.5}`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	variables, err = LoadVariablesFile(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadVariablesFile returned an error: %v", err)
+	}
+	if variables["count"] != (Variable{Type: "int", Value: "2"}) || variables["rate"] != (Variable{Type: "float", Value: "0.5"}) {
+		t.Errorf("unexpected JSON variables: %+v", variables)
+	}
+}
@@ -20,21 +20,11 @@ func NewTestDefinitionParser() TestDefinitionParser {
 }
 
 func (p *TestDefinitionParserImpl) ParseYaml(data []byte, ext string) (*TestDefinition, error) {
-	var def TestDefinition
-	err := yaml.Unmarshal(data, &def)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling YAML: %v", err)
-	}
-	return &def, nil
+	return p.parse(data, ".yaml")
 }
 
 func (p *TestDefinitionParserImpl) ParseJson(data []byte, ext string) (*TestDefinition, error) {
-	var def TestDefinition
-	err := json.Unmarshal(data, &def)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling JSON: %v", err)
-	}
-	return &def, nil
+	return p.parse(data, ".json")
 }
 
 func (p *TestDefinitionParserImpl) Parse(data []byte, ext string) (*TestDefinition, error) {
@@ -42,13 +32,30 @@ func (p *TestDefinitionParserImpl) Parse(data []byte, ext string) (*TestDefiniti
 	if !slices.Contains(supportedExts, ext) {
 		return nil, fmt.Errorf("unsupported file extension: %s", ext)
 	}
+	return p.parse(data, ext)
+}
 
+func (p *TestDefinitionParserImpl) parse(data []byte, ext string) (*TestDefinition, error) {
+	var def TestDefinition
+	if err := unmarshalByExt(data, ext, &def); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// unmarshalByExt decodes YAML or JSON data into v based on the file extension
+func unmarshalByExt(data []byte, ext string, v interface{}) error {
 	switch ext {
-	case ".yaml":
-		return p.ParseYaml(data, ext)
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("error unmarshalling YAML: %v", err)
+		}
 	case ".json":
-		return p.ParseJson(data, ext)
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("error unmarshalling JSON: %v", err)
+		}
 	default:
-		return nil, fmt.Errorf("unsupported file extension: %s", ext)
+		return fmt.Errorf("unsupported file extension: %s", ext)
 	}
+	return nil
 }
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvironmentProfile is a named set of variables for a target environment, such as
+// local, staging or production. Profiles are loaded from httpprobe.env.<name>.yaml files
+// and their variables are layered under the variables of every test definition.
+type EnvironmentProfile struct {
+	// Name of the environment, e.g. "staging"
+	Name string `yaml:"name" json:"name"`
+	// Path is the path to the profile file
+	Path string `yaml:"-" json:"-"`
+	// Variables available to every test definition in the run
+	Variables map[string]Variable `yaml:"variables" json:"variables"`
+}
+
+// EnvironmentProfileFileName returns the file name of the profile for an environment,
+// e.g. httpprobe.env.staging.yaml
+func EnvironmentProfileFileName(name string, ext string) string {
+	return fmt.Sprintf("httpprobe.env.%s%s", name, ext)
+}
+
+// LoadEnvironmentProfile loads the profile for the named environment. The name can also be
+// a path to a profile file. Otherwise httpprobe.env.<name>.yaml (or .json) is looked up in
+// each of dirs, in order.
+func LoadEnvironmentProfile(name string, dirs []string) (*EnvironmentProfile, error) {
+	return defaultInterpolator.LoadEnvironmentProfile(name, dirs)
+}
+
+// LoadEnvironmentProfile loads the profile for the named environment, resolving its
+// variables with the Interpolator's secret providers. Relative paths in the profile are
+// resolved against the directory of the profile file. See the package-level
+// LoadEnvironmentProfile.
+func (ip *Interpolator) LoadEnvironmentProfile(name string, dirs []string) (*EnvironmentProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	path, err := findEnvironmentProfile(name, dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading environment profile %s: %w", path, err)
+	}
+
+	profile := &EnvironmentProfile{}
+	if err := unmarshalByExt(data, filepath.Ext(path), profile); err != nil {
+		return nil, fmt.Errorf("error parsing environment profile %s: %w", path, err)
+	}
+
+	profile.Path = path
+	if profile.Name == "" {
+		profile.Name = name
+	}
+	if profile.Variables == nil {
+		profile.Variables = make(map[string]Variable)
+	}
+
+	profileInterpolator := *ip
+	profileInterpolator.BaseDir = filepath.Dir(path)
+	if err := profileInterpolator.ResolveVariables(profile.Variables, nil); err != nil {
+		return nil, fmt.Errorf("error resolving variables in environment profile %s: %w", path, err)
+	}
+
+	return profile, nil
+}
+
+// findEnvironmentProfile returns the path of the profile file for the named environment
+func findEnvironmentProfile(name string, dirs []string) (string, error) {
+	ext := filepath.Ext(name)
+	if ext == ".yaml" || ext == ".yml" || ext == ".json" || strings.ContainsRune(name, os.PathSeparator) {
+		if _, err := os.Stat(name); err != nil {
+			return "", fmt.Errorf("environment profile %s not found: %w", name, err)
+		}
+		return name, nil
+	}
+
+	var searched []string
+	for _, dir := range dirs {
+		for _, ext := range []string{".yaml", ".json"} {
+			path := filepath.Join(dir, EnvironmentProfileFileName(name, ext))
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+			searched = append(searched, path)
+		}
+	}
+
+	return "", fmt.Errorf("environment profile %q not found, looked for %s", name, strings.Join(searched, ", "))
+}
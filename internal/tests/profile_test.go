@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvironmentProfile(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+
+	profile := `
+variables:
+  host:
+    value: staging.example.com
+  base_url:
+    value: "https://${host}/v1"
+  retries:
+    type: int
+    value: "3"
+`
+	if err := os.WriteFile(filepath.Join(second, "httpprobe.env.staging.yaml"), []byte(profile), 0644); err != nil {
+		t.Fatalf("error writing profile: %v", err)
+	}
+
+	loaded, err := LoadEnvironmentProfile("staging", []string{first, second})
+	if err != nil {
+		t.Fatalf("LoadEnvironmentProfile returned an error: %v", err)
+	}
+
+	if loaded.Name != "staging" {
+		t.Errorf("Name = %q, want %q", loaded.Name, "staging")
+	}
+	if loaded.Path != filepath.Join(second, "httpprobe.env.staging.yaml") {
+		t.Errorf("Path = %q, want the profile in the second directory", loaded.Path)
+	}
+	if loaded.Variables["base_url"].Value != "https://staging.example.com/v1" {
+		t.Errorf("base_url = %q, want %q", loaded.Variables["base_url"].Value, "https://staging.example.com/v1")
+	}
+	if loaded.Variables["retries"].Type != "int" {
+		t.Errorf("retries type = %q, want %q", loaded.Variables["retries"].Type, "int")
+	}
+
+	// Profiles can be referenced by path
+	byPath, err := LoadEnvironmentProfile(loaded.Path, nil)
+	if err != nil {
+		t.Fatalf("LoadEnvironmentProfile by path returned an error: %v", err)
+	}
+	if byPath.Variables["host"].Value != "staging.example.com" {
+		t.Errorf("host = %q, want %q", byPath.Variables["host"].Value, "staging.example.com")
+	}
+}
+
+func TestLoadEnvironmentProfile_NotFound(t *testing.T) {
+	if _, err := LoadEnvironmentProfile("production", []string{t.TempDir()}); err == nil {
+		t.Errorf("expected an error for a missing profile")
+	}
+
+	profile, err := LoadEnvironmentProfile("", nil)
+	if err != nil || profile != nil {
+		t.Errorf("LoadEnvironmentProfile(\"\") = %v, %v, want nil, nil", profile, err)
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// providerRefPattern matches secret provider references: ${provider:reference} or
+// ${provider:reference:-default}. References cannot start with "-", so ${name:-default}
+// is a variable with a default value.
+var providerRefPattern = regexp.MustCompile(`\$\{([a-z][a-z0-9_]*):([^}\-][^}]*?)(?::-([^}]*))?\}`)
+
+// SecretProvider resolves the values of ${<name>:<reference>} references from an external
+// source, such as a file, a local password manager or a secret manager. Providers are
+// registered by name in SecretProviders.
+type SecretProvider interface {
+	// Resolve returns the value for reference. baseDir is the directory of the test
+	// definition being executed, for providers that work with relative paths.
+	Resolve(reference string, baseDir string) (string, error)
+}
+
+// SecretProviderFunc adapts an ordinary function to a SecretProvider
+type SecretProviderFunc func(reference string, baseDir string) (string, error)
+
+// Resolve calls f(reference, baseDir)
+func (f SecretProviderFunc) Resolve(reference string, baseDir string) (string, error) {
+	return f(reference, baseDir)
+}
+
+// SecretProviders maps provider names, as used in references, to providers
+type SecretProviders map[string]SecretProvider
+
+// ErrCommandSecretsDisabled is returned for ${cmd:...} references when command secrets
+// have not been enabled
+var ErrCommandSecretsDisabled = errors.New("command secrets are disabled, enable them with --allow-cmd-secrets")
+
+// DefaultSecretProviders returns the providers available in every run: file, and cmd in
+// its disabled form
+func DefaultSecretProviders() SecretProviders {
+	return SecretProviders{
+		"file": FileSecretProvider{},
+		"cmd": SecretProviderFunc(func(reference string, baseDir string) (string, error) {
+			return "", ErrCommandSecretsDisabled
+		}),
+	}
+}
+
+// FileSecretProvider reads secrets from files: ${file:path}. Relative paths are resolved
+// against the directory of the test definition and trailing whitespace is trimmed.
+type FileSecretProvider struct{}
+
+// Resolve reads the file at path
+func (FileSecretProvider) Resolve(path string, baseDir string) (string, error) {
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(data), " \t\r\n"), nil
+}
+
+// defaultCommandTimeout is how long a secret command may run when no timeout is set
+const defaultCommandTimeout = 30 * time.Second
+
+// CommandSecretProvider runs a local command and uses its standard output as the secret:
+// ${cmd:pass show api/token}. The command is run by the shell in the directory of the test
+// definition and trailing whitespace is trimmed from its output.
+type CommandSecretProvider struct {
+	// Timeout is how long the command may run. Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// Resolve runs command and returns its output
+func (p CommandSecretProvider) Resolve(command string, baseDir string) (string, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = baseDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// The command line is not included as it may contain secrets itself
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+
+	return strings.TrimRight(stdout.String(), " \t\r\n"), nil
+}
+
+// providers returns the Interpolator's secret providers, or the default providers
+func (ip *Interpolator) providers() SecretProviders {
+	if ip != nil && ip.Providers != nil {
+		return ip.Providers
+	}
+	return defaultSecretProviders
+}
+
+// defaultSecretProviders is used when an Interpolator has no providers set
+var defaultSecretProviders = DefaultSecretProviders()
+
+// resolveProviderRef returns the value of a ${provider:reference} reference from the
+// registered provider. References to unknown providers are not resolved. Resolved values
+// are registered with the Interpolator's secret store.
+func (ip *Interpolator) resolveProviderRef(ref *templateRef) (string, string, error) {
+	provider, exists := ip.providers()[ref.name]
+	if !exists {
+		return "", ref.String(), nil
+	}
+
+	value, err := provider.Resolve(ref.reference, ip.baseDir())
+	if err != nil {
+		// Fall back to the default value if one was given
+		if ref.hasFallback {
+			return ref.fallback, "", nil
+		}
+		return "", "", fmt.Errorf("error resolving ${%s:...}: %w", ref.name, err)
+	}
+
+	ip.secrets().Add(value)
+	return value, "", nil
+}
+
+// hasProviderReference reports whether value references a registered secret provider
+func (ip *Interpolator) hasProviderReference(value string) bool {
+	providers := ip.providers()
+	for _, match := range providerRefPattern.FindAllStringSubmatch(value, -1) {
+		if _, exists := providers[match[1]]; exists {
+			return true
+		}
+	}
+	return false
+}
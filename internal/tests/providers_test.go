@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeSecretProvider resolves references from a map, standing in for a secret manager
+type fakeSecretProvider struct {
+	secrets map[string]string
+	calls   []string
+}
+
+func (p *fakeSecretProvider) Resolve(reference string, baseDir string) (string, error) {
+	p.calls = append(p.calls, reference)
+	value, ok := p.secrets[reference]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+func TestInterpolateVariables_SecretProviders(t *testing.T) {
+	vault := &fakeSecretProvider{secrets: map[string]string{
+		"kv/api#token": "vault-token-123",
+	}}
+	ip := &Interpolator{
+		Providers: SecretProviders{"vault": vault},
+		Secrets:   NewSecretStore(),
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "Provider reference",
+			input:    "Bearer ${vault:kv/api#token}",
+			expected: "Bearer vault-token-123",
+		},
+		{
+			name:     "Missing secret with default",
+			input:    "${vault:kv/missing:-fallback}",
+			expected: "fallback",
+		},
+		{
+			name:      "Missing secret",
+			input:     "${vault:kv/missing}",
+			expectErr: true,
+		},
+		{
+			name:     "Unknown provider is left unchanged",
+			input:    "${aws:prod/db}",
+			expected: "${aws:prod/db}",
+		},
+		{
+			name:     "Variable default is not a provider reference",
+			input:    "${vault:-fallback}",
+			expected: "fallback",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ip.InterpolateVariables(tt.input, nil)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("InterpolateVariables(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	if got := ip.Secrets.Redact("token vault-token-123"); got != "token [REDACTED]" {
+		t.Errorf("expected provider values to be registered as secrets, got %q", got)
+	}
+}
+
+func TestResolveVariables_ProviderValuesAreSecret(t *testing.T) {
+	ip := &Interpolator{
+		Providers: SecretProviders{"fake": &fakeSecretProvider{secrets: map[string]string{"db": "hunter22"}}},
+	}
+	variables := map[string]Variable{
+		"db_password": {Type: "string", Value: "${fake:db}"},
+		"db_user":     {Type: "string", Value: "admin"},
+	}
+
+	if err := ip.ResolveVariables(variables, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if variables["db_password"].Value != "hunter22" || !variables["db_password"].Secret {
+		t.Errorf("expected db_password to be resolved and secret, got %+v", variables["db_password"])
+	}
+	if variables["db_user"].Secret {
+		t.Errorf("expected db_user not to be secret")
+	}
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token.txt"), []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ip := &Interpolator{BaseDir: dir}
+	result, err := ip.InterpolateVariables("${file:token.txt}", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "file-token" {
+		t.Errorf("expected file-token, got %q", result)
+	}
+
+	if _, err := ip.InterpolateVariables("${file:missing.txt}", nil); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestCommandSecretProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	// Commands are disabled by default
+	_, err := (&Interpolator{}).InterpolateVariables("${cmd:echo secret}", nil)
+	if !errors.Is(err, ErrCommandSecretsDisabled) {
+		t.Errorf("expected ErrCommandSecretsDisabled, got %v", err)
+	}
+
+	providers := DefaultSecretProviders()
+	providers["cmd"] = CommandSecretProvider{}
+	ip := &Interpolator{Providers: providers}
+
+	result, err := ip.InterpolateVariables("${cmd:printf 'cmd-secret\\n'}", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "cmd-secret" {
+		t.Errorf("expected cmd-secret, got %q", result)
+	}
+
+	if _, err := ip.InterpolateVariables("${cmd:exit 3}", nil); err == nil {
+		t.Errorf("expected error for failing command")
+	}
+
+	// Provider references in environment and variable values are not resolved
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	ip.Env = NewEnvironment()
+	ip.Env.Set("HOSTILE", "${cmd:touch " + marker + "}")
+	variables := map[string]Variable{"hostile": {Value: "${cmd:touch " + marker + "}"}}
+
+	for _, input := range []string{"${env:HOSTILE}", "${hostile}", "${upper(${hostile})}"} {
+		if _, err := ip.InterpolateVariables(input, variables); err != nil {
+			t.Errorf("InterpolateVariables(%q) returned an error: %v", input, err)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("expected commands in values not to be run")
+	}
+}
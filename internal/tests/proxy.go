@@ -0,0 +1,35 @@
+package tests
+
+import "github.com/mrfoh/httpprobe/pkg/easyreq"
+
+// ProxyConfig configures the proxy for the requests of a test definition. It replaces the
+// proxy set with the --proxy flag.
+type ProxyConfig struct {
+	// URL of the proxy, e.g. "http://proxy.internal:3128" or "socks5://localhost:1080". An
+	// empty URL connects directly, ignoring the proxy environment variables.
+	URL string `yaml:"url" json:"url"`
+	// NoProxy lists hosts that are connected to directly instead of through the proxy
+	NoProxy []string `yaml:"no_proxy" json:"no_proxy"`
+}
+
+// Options interpolates the configuration with the variables and returns the easyreq proxy
+// options for it, along with the first interpolation error.
+func (c *ProxyConfig) Options(ip *Interpolator, variables map[string]Variable) (*easyreq.ProxyOptions, error) {
+	values := ip.lenient(variables)
+	options := &easyreq.ProxyOptions{URL: values.interpolate("proxy url", c.URL)}
+	for _, host := range c.NoProxy {
+		options.NoProxy = append(options.NoProxy, values.interpolate("proxy no_proxy", host))
+	}
+	return options, values.err
+}
+
+// ResolveOptions interpolates the addresses of a test definition's resolve entries with
+// the variables and returns them along with the first interpolation error.
+func ResolveOptions(resolve map[string]string, ip *Interpolator, variables map[string]Variable) (map[string]string, error) {
+	values := ip.lenient(variables)
+	resolved := make(map[string]string, len(resolve))
+	for host, address := range resolve {
+		resolved[host] = values.interpolate("resolve address for "+host, address)
+	}
+	return resolved, values.err
+}
@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"testing"
+)
+
+func TestProxyConfigOptions(t *testing.T) {
+	variables := map[string]Variable{
+		"proxy_host": {Type: "string", Value: "proxy.internal"},
+		"staging_ip": {Type: "string", Value: "10.0.0.5"},
+	}
+	ip := &Interpolator{}
+
+	config := &ProxyConfig{URL: "http://${proxy_host}:3128", NoProxy: []string{"localhost", "${proxy_host}"}}
+	options, err := config.Options(ip, variables)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if options.URL != "http://proxy.internal:3128" || len(options.NoProxy) != 2 || options.NoProxy[1] != "proxy.internal" {
+		t.Errorf("unexpected proxy options: %+v", options)
+	}
+
+	resolve, err := ResolveOptions(map[string]string{"api.example.com:443": "${staging_ip}"}, ip, variables)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolve["api.example.com:443"] != "10.0.0.5" {
+		t.Errorf("unexpected resolve entries: %v", resolve)
+	}
+
+	// Interpolation errors are reported and the value is kept
+	config = &ProxyConfig{URL: "${cmd:echo http://proxy.internal}"}
+	options, err = config.Options(ip, variables)
+	if err == nil || options.URL != "${cmd:echo http://proxy.internal}" {
+		t.Errorf("expected an interpolation error, got %v with %+v", err, options)
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RandomSource is a goroutine safe source of random values used by template functions
+// and fake data generators. Sources created with the same seed produce the same values.
+type RandomSource struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandomSource creates a RandomSource seeded with seed
+func NewRandomSource(seed int64) *RandomSource {
+	return &RandomSource{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Intn returns a random number in [0, n)
+func (r *RandomSource) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Intn(n)
+}
+
+// Int63 returns a random non-negative int64
+func (r *RandomSource) Int63() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int63()
+}
+
+// Read fills p with random bytes. It implements io.Reader so the source can be used to
+// generate UUIDs.
+func (r *RandomSource) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Read(p)
+}
+
+// DeriveSeed derives a seed for a named scope, such as a test definition, from the run seed.
+// Giving each scope its own source keeps generated values reproducible when scopes run concurrently.
+func DeriveSeed(seed int64, key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return seed ^ int64(h.Sum64())
+}
+
+// DefinitionSeed derives the seed for a test definition from the run seed. The seed depends
+// on the definition's path as well as its name, so definitions with the same name in
+// different directories generate different values.
+func DefinitionSeed(seed int64, def *TestDefinition) int64 {
+	return DeriveSeed(seed, def.Path+"\x00"+def.Name)
+}
+
+// random is the package-level source, used when an Interpolator has no source of its own
+var random = NewRandomSource(time.Now().UnixNano())
+
+// SeedRandom reseeds the package-level random source, making the values generated by
+// interpolation without a dedicated RandomSource reproducible
+func SeedRandom(seed int64) {
+	random = NewRandomSource(seed)
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInterpolator_SeededRandomSource(t *testing.T) {
+	input := "${uuid()} ${random(12)} ${fake.email()}"
+
+	generate := func(seed int64) string {
+		interpolator := &Interpolator{Random: NewRandomSource(seed)}
+		result, err := interpolator.InterpolateVariables(input, nil)
+		if err != nil {
+			t.Fatalf("InterpolateVariables returned an error: %v", err)
+		}
+		return result
+	}
+
+	if first, second := generate(7), generate(7); first != second {
+		t.Errorf("same seed generated different values: %q and %q", first, second)
+	}
+	if first, second := generate(7), generate(8); first == second {
+		t.Errorf("different seeds generated the same values: %q", first)
+	}
+}
+
+func TestInterpolator_Fork(t *testing.T) {
+	generate := func() []string {
+		parent := &Interpolator{Random: NewRandomSource(99)}
+		forks := []*Interpolator{parent.Fork(), parent.Fork(), parent.Fork()}
+
+		// Use the forks concurrently, in no particular order
+		results := make([]string, len(forks))
+		var wg sync.WaitGroup
+		for i, fork := range forks {
+			wg.Add(1)
+			go func(i int, fork *Interpolator) {
+				defer wg.Done()
+				results[i], _ = fork.InterpolateVariables("${uuid()}", nil)
+			}(i, fork)
+		}
+		wg.Wait()
+		return results
+	}
+
+	first, second := generate(), generate()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("fork %d generated %q and %q", i, first[i], second[i])
+		}
+	}
+	if first[0] == first[1] {
+		t.Errorf("forks generated the same value: %q", first[0])
+	}
+}
+
+func TestRandomSource_ConcurrentUse(t *testing.T) {
+	source := NewRandomSource(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				source.Intn(10)
+				processUUIDFunction(source)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDeriveSeed(t *testing.T) {
+	if DeriveSeed(1, "users") != DeriveSeed(1, "users") {
+		t.Errorf("DeriveSeed is not deterministic")
+	}
+	if DeriveSeed(1, "users") == DeriveSeed(1, "orders") {
+		t.Errorf("DeriveSeed returned the same seed for different keys")
+	}
+}
+
+func TestDefinitionSeed(t *testing.T) {
+	users := &TestDefinition{Name: "smoke", Path: "users/smoke.test.yaml"}
+	orders := &TestDefinition{Name: "smoke", Path: "orders/smoke.test.yaml"}
+
+	if DefinitionSeed(1, users) != DefinitionSeed(1, &TestDefinition{Name: "smoke", Path: "users/smoke.test.yaml"}) {
+		t.Errorf("DefinitionSeed is not deterministic")
+	}
+	if DefinitionSeed(1, users) == DefinitionSeed(1, orders) {
+		t.Errorf("DefinitionSeed returned the same seed for definitions with the same name in different files")
+	}
+}
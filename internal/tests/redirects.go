@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseFollowRedirects parses the follow_redirects setting of a request: true follows
+// redirects up to the client's limit, false does not follow them and a number is the
+// maximum number of redirects to follow. It returns nil when the setting is not set.
+func ParseFollowRedirects(value any) (*int, error) {
+	var maxRedirects int
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		if v {
+			return nil, nil
+		}
+		maxRedirects = 0
+	case int:
+		maxRedirects = v
+	case float64:
+		if v != float64(int(v)) {
+			return nil, fmt.Errorf("follow_redirects must be true, false or a number of redirects, got %v", v)
+		}
+		maxRedirects = int(v)
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "true":
+			return nil, nil
+		case "false":
+			maxRedirects = 0
+		default:
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("follow_redirects must be true, false or a number of redirects, got %q", v)
+			}
+			maxRedirects = n
+		}
+	default:
+		return nil, fmt.Errorf("follow_redirects must be true, false or a number of redirects, got %T", value)
+	}
+
+	if maxRedirects < 0 {
+		return nil, fmt.Errorf("follow_redirects must not be negative: %d", maxRedirects)
+	}
+	return &maxRedirects, nil
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestParseFollowRedirects(t *testing.T) {
+	tests := []struct {
+		value     any
+		expected  int
+		follow    bool
+		expectErr bool
+	}{
+		{value: nil, follow: true},
+		{value: true, follow: true},
+		{value: false, expected: 0},
+		{value: 3, expected: 3},
+		{value: float64(5), expected: 5},
+		{value: "2", expected: 2},
+		{value: -1, expectErr: true},
+		{value: "sometimes", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		maxRedirects, err := ParseFollowRedirects(tt.value)
+		if (err != nil) != tt.expectErr {
+			t.Errorf("ParseFollowRedirects(%v) error = %v, expectErr %v", tt.value, err, tt.expectErr)
+			continue
+		}
+		if tt.expectErr {
+			continue
+		}
+		if tt.follow {
+			if maxRedirects != nil {
+				t.Errorf("ParseFollowRedirects(%v) = %d, expected the default limit", tt.value, *maxRedirects)
+			}
+		} else if maxRedirects == nil || *maxRedirects != tt.expected {
+			t.Errorf("ParseFollowRedirects(%v) = %v, expected %d", tt.value, maxRedirects, tt.expected)
+		}
+	}
+}
+
+func TestExecCase_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+			http.Redirect(w, r, "/old", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{Secrets: NewSecretStore()}
+	testCase := &TestCase{
+		Title: "follows",
+		Request: Request{
+			Method: "GET",
+			URL:    server.URL + "/old",
+			Assertions: map[string]interface{}{
+				"status":    200,
+				"redirects": map[string]interface{}{"count": 1, "final_url": "/new", "statuses": []interface{}{301}},
+			},
+		},
+	}
+
+	result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected the redirect to be followed: %v", result.FailureReasons)
+	}
+
+	// Without following redirects the 301 itself is asserted
+	testCase.Request.FollowRedirects = false
+	testCase.Request.Assertions = map[string]interface{}{
+		"status":    301,
+		"headers":   map[string]interface{}{"Location": "/new"},
+		"redirects": map[string]interface{}{"count": 0},
+	}
+	result, err = suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected the redirect response to be returned: %v", result.FailureReasons)
+	}
+
+	// Cookies set by redirects can be asserted, and redirecting more often than allowed
+	// returns the last redirect response
+	testCase.Request.URL = server.URL + "/login"
+	testCase.Request.FollowRedirects = 1
+	testCase.Request.Assertions = map[string]interface{}{
+		"status":    301,
+		"headers":   map[string]interface{}{"Location": "/new"},
+		"redirects": map[string]interface{}{"count": 1, "final_url": "/old", "statuses": []interface{}{302}},
+		"cookies":   map[string]interface{}{"session": "abc"},
+	}
+	result, err = suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected the last redirect response with the chain followed: %v", result.FailureReasons)
+	}
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VariableCycleError is returned when variable values reference each other in a cycle
+type VariableCycleError struct {
+	// Path lists the variables that form the cycle, starting and ending with the same name
+	Path []string
+}
+
+func (e *VariableCycleError) Error() string {
+	return fmt.Sprintf("variable reference cycle detected: %s", strings.Join(e.Path, " -> "))
+}
+
+// ResolveVariables interpolates the values of variables in place. References between
+// variables are resolved in dependency order, so chains of any depth resolve regardless
+// of map iteration order. References to names that are not in variables are resolved
+// from parent, and a variable that references its own name refers to the parent value,
+// e.g. a suite variable "${base_url}/v2" extending a definition-level base_url.
+//
+// Variables that are part of a reference cycle are left unresolved and a
+// *VariableCycleError is returned once every other variable has been resolved.
+func ResolveVariables(variables map[string]Variable, parent map[string]Variable) error {
+	return defaultInterpolator.ResolveVariables(variables, parent)
+}
+
+// ResolveVariables interpolates the values of variables in place, resolving references
+// between variables in dependency order. See the package-level ResolveVariables.
+func (ip *Interpolator) ResolveVariables(variables map[string]Variable, parent map[string]Variable) error {
+	if variables == nil {
+		return nil
+	}
+
+	// Build the dependency graph between variables in this scope
+	dependencies := make(map[string][]string, len(variables))
+	for name, variable := range variables {
+		for _, ref := range referencedVariableNames(variable.Value) {
+			if ref == name {
+				continue
+			}
+			if _, exists := variables[ref]; exists {
+				dependencies[name] = append(dependencies[name], ref)
+			}
+		}
+	}
+
+	order, cyclic, cycleErr := sortVariables(dependencies, variables)
+
+	// The resolution scope starts from the parent variables and grows as variables are resolved
+	scope := make(map[string]Variable, len(parent)+len(variables))
+	for k, v := range parent {
+		scope[k] = v
+	}
+
+	for _, name := range order {
+		variable := variables[name]
+		if variable.Value != "" && !cyclic[name] {
+			// Values read from secret providers are secret
+			if ip.hasProviderReference(variable.Value) {
+				variable.Secret = true
+			}
+			interpolated, err := ip.InterpolateVariables(variable.Value, scope)
+			if err != nil {
+				return fmt.Errorf("error interpolating variables in variable %s: %w", name, err)
+			}
+			variable.Value = interpolated
+			variables[name] = variable
+		}
+		scope[name] = variable
+	}
+
+	if cycleErr != nil {
+		return cycleErr
+	}
+
+	return nil
+}
+
+// referencedVariableNames returns the names of all ${name} references in a value,
+// including references used as function arguments
+func referencedVariableNames(value string) []string {
+	var names []string
+	for _, match := range variableRefPattern.FindAllStringSubmatch(value, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// sortVariables orders variable names so that every variable comes after the variables
+// it depends on. Names are visited alphabetically so the order is deterministic.
+// It returns the order, the set of variables that are part of a cycle and an error
+// describing the first cycle found.
+func sortVariables(dependencies map[string][]string, variables map[string]Variable) ([]string, map[string]bool, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	state := make(map[string]int, len(names))
+	cyclic := make(map[string]bool)
+	order := make([]string, 0, len(names))
+	var cycleErr error
+	var path []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		switch state[name] {
+		case visited:
+			return
+		case visiting:
+			// Mark every variable between the first occurrence of name and the end of the path
+			start := len(path) - 1
+			for start >= 0 && path[start] != name {
+				start--
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			for _, n := range cycle {
+				cyclic[n] = true
+			}
+			if cycleErr == nil {
+				cycleErr = &VariableCycleError{Path: cycle}
+			}
+			return
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		deps := append([]string{}, dependencies[name]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			visit(dep)
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		order = append(order, name)
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return order, cyclic, cycleErr
+}
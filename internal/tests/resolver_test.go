@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveVariables_Chains(t *testing.T) {
+	// Run several times since map iteration order is random
+	for i := 0; i < 20; i++ {
+		variables := map[string]Variable{
+			"users_url": {Type: "string", Value: "${api_url}/users"},
+			"api_url":   {Type: "string", Value: "${base_url}/v1"},
+			"base_url":  {Type: "string", Value: "https://${host}"},
+			"host":      {Type: "string", Value: "api.example.com"},
+		}
+
+		if err := ResolveVariables(variables, nil); err != nil {
+			t.Fatalf("ResolveVariables returned an error: %v", err)
+		}
+
+		expected := "https://api.example.com/v1/users"
+		if variables["users_url"].Value != expected {
+			t.Fatalf("users_url = %q, want %q", variables["users_url"].Value, expected)
+		}
+	}
+}
+
+func TestResolveVariables_ParentScope(t *testing.T) {
+	parent := map[string]Variable{
+		"base_url": {Type: "string", Value: "https://api.example.com"},
+		"version":  {Type: "string", Value: "v1"},
+	}
+	variables := map[string]Variable{
+		"base_url":  {Type: "string", Value: "${base_url}/${version}"},
+		"users_url": {Type: "string", Value: "${base_url}/users"},
+	}
+
+	if err := ResolveVariables(variables, parent); err != nil {
+		t.Fatalf("ResolveVariables returned an error: %v", err)
+	}
+
+	if variables["base_url"].Value != "https://api.example.com/v1" {
+		t.Errorf("base_url = %q, want %q", variables["base_url"].Value, "https://api.example.com/v1")
+	}
+	if variables["users_url"].Value != "https://api.example.com/v1/users" {
+		t.Errorf("users_url = %q, want %q", variables["users_url"].Value, "https://api.example.com/v1/users")
+	}
+	if parent["base_url"].Value != "https://api.example.com" {
+		t.Errorf("parent base_url was modified: %q", parent["base_url"].Value)
+	}
+}
+
+func TestResolveVariables_Cycle(t *testing.T) {
+	variables := map[string]Variable{
+		"a":     {Type: "string", Value: "${b}"},
+		"b":     {Type: "string", Value: "${c}"},
+		"c":     {Type: "string", Value: "${a}"},
+		"other": {Type: "string", Value: "${host}/path"},
+		"host":  {Type: "string", Value: "localhost"},
+	}
+
+	err := ResolveVariables(variables, nil)
+
+	var cycleErr *VariableCycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("ResolveVariables error = %v, want *VariableCycleError", err)
+	}
+	if got := strings.Join(cycleErr.Path, " -> "); got != "a -> b -> c -> a" {
+		t.Errorf("cycle path = %q, want %q", got, "a -> b -> c -> a")
+	}
+
+	// Variables outside the cycle are still resolved
+	if variables["other"].Value != "localhost/path" {
+		t.Errorf("other = %q, want %q", variables["other"].Value, "localhost/path")
+	}
+	if variables["a"].Value != "${b}" {
+		t.Errorf("a = %q, want it left unresolved", variables["a"].Value)
+	}
+}
+
+func TestResolveVariables_FunctionsEvaluatedOnce(t *testing.T) {
+	variables := map[string]Variable{
+		"length":   {Type: "int", Value: "12"},
+		"id":       {Type: "string", Value: "${random(${length})}"},
+		"user_url": {Type: "string", Value: "/users/${id}"},
+		"copy":     {Type: "string", Value: "${id}"},
+	}
+
+	if err := ResolveVariables(variables, nil); err != nil {
+		t.Fatalf("ResolveVariables returned an error: %v", err)
+	}
+
+	id := variables["id"].Value
+	if len(id) != 12 {
+		t.Fatalf("id = %q, want a 12 character random string", id)
+	}
+	if variables["user_url"].Value != "/users/"+id || variables["copy"].Value != id {
+		t.Errorf("dependent variables do not share the generated value: %q, %q",
+			variables["user_url"].Value, variables["copy"].Value)
+	}
+}
@@ -1,5 +1,17 @@
 package tests
 
+// RunInfo describes a test run. It is included in the header of every report.
+type RunInfo struct {
+	// Seed is the seed used for random values and generated data; passing it to a
+	// later run with --seed reproduces the same values
+	Seed int64
+	// Environment is the name of the selected environment profile, if any
+	Environment string
+	// Cancelled is the reason the run was stopped before all test cases ran, e.g. an
+	// interrupt. The results of a cancelled run are partial.
+	Cancelled string
+}
+
 // ExecutionResult is the result of executing a test definition
 type TestDefinitionExecResult struct {
 	// Path is the path to the test definition file
@@ -20,6 +32,12 @@ type TestSuiteResult struct {
 type TestCaseResult struct {
 	// Passed indicates if the test case passed
 	Passed bool
+	// Errored indicates the test case could not be executed, e.g. because of an undefined variable
+	Errored bool
+	// TimedOut indicates the test case's request did not complete within its timeout
+	TimedOut bool
+	// Cancelled indicates the run was stopped before the test case completed
+	Cancelled bool
 	// Timing is the time taken to execute the test case
 	Timing float64
 	// FailureReasons contains the detailed reasons for failure (validation errors)
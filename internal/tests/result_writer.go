@@ -1,7 +1,7 @@
 package tests
 
 type TestResultWriter interface {
-	Write(results map[string]TestDefinitionExecResult)
+	Write(results map[string]TestDefinitionExecResult, info RunInfo)
 }
 
 func NewResultWriter(outputType string, outputFile string) TestResultWriter {
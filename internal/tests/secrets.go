@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces secret values in logs, reports and failure messages
+const RedactedValue = "[REDACTED]"
+
+// minSecretLength is the shortest secret value that is redacted. Shorter values would
+// redact unrelated text, e.g. every "1" in a report.
+const minSecretLength = 4
+
+// sensitiveNames are name fragments that mark an exported value as secret
+var sensitiveNames = []string{
+	"token", "secret", "password", "passwd", "api_key", "apikey", "authorization",
+	"credential", "private_key", "session", "cookie",
+}
+
+// IsSensitiveName reports whether a variable name or JSON field looks like it holds a secret,
+// e.g. access_token, client_secret or password
+func IsSensitiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, fragment := range sensitiveNames {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretStore collects the values of secret variables during a run and redacts them from
+// any text. It is safe for concurrent use, and a nil *SecretStore redacts nothing.
+type SecretStore struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// NewSecretStore creates an empty SecretStore
+func NewSecretStore() *SecretStore {
+	return &SecretStore{}
+}
+
+// Add registers secret values to be redacted
+func (s *SecretStore) Add(values ...string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, value := range values {
+		if len(value) < minSecretLength || s.contains(value) {
+			continue
+		}
+		s.values = append(s.values, value)
+	}
+
+	// Replace longer secrets first so a secret containing another is fully redacted
+	sort.Slice(s.values, func(i, j int) bool {
+		return len(s.values[i]) > len(s.values[j])
+	})
+}
+
+// AddVariables registers the values of all secret variables in variables
+func (s *SecretStore) AddVariables(variables map[string]Variable) {
+	for _, variable := range variables {
+		if variable.Secret {
+			s.Add(variable.Value)
+		}
+	}
+}
+
+func (s *SecretStore) contains(value string) bool {
+	for _, v := range s.values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces every registered secret value in text with RedactedValue
+func (s *SecretStore) Redact(text string) string {
+	if s == nil || text == "" {
+		return text
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, value := range s.values {
+		text = strings.ReplaceAll(text, value, RedactedValue)
+	}
+	return text
+}
+
+// RedactAll redacts each string in texts, returning a new slice
+func (s *SecretStore) RedactAll(texts []string) []string {
+	if texts == nil {
+		return nil
+	}
+
+	redacted := make([]string, len(texts))
+	for i, text := range texts {
+		redacted[i] = s.Redact(text)
+	}
+	return redacted
+}
+
+// RedactVariables returns a copy of variables that is safe to log: secret variables have
+// their values replaced and other values have any embedded secrets redacted
+func (s *SecretStore) RedactVariables(variables map[string]Variable) map[string]Variable {
+	if variables == nil {
+		return nil
+	}
+
+	redacted := make(map[string]Variable, len(variables))
+	for name, variable := range variables {
+		if variable.Secret {
+			variable.Value = RedactedValue
+		} else {
+			variable.Value = s.Redact(variable.Value)
+		}
+		redacted[name] = variable
+	}
+	return redacted
+}
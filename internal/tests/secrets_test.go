@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestIsSensitiveName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"access_token", true},
+		{"client_secret", true},
+		{"Password", true},
+		{"X-Api-Key", false},
+		{"api_key", true},
+		{"user_id", false},
+		{"email", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSensitiveName(tt.name); got != tt.expected {
+				t.Errorf("IsSensitiveName(%q) = %v, want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSecretStore_Redact(t *testing.T) {
+	store := NewSecretStore()
+	store.Add("s3cret", "s3cret-extended", "abc")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Single secret",
+			input:    "Authorization: Bearer s3cret",
+			expected: "Authorization: Bearer [REDACTED]",
+		},
+		{
+			name:     "Longer secret is redacted whole",
+			input:    "token=s3cret-extended",
+			expected: "token=[REDACTED]",
+		},
+		{
+			name:     "Short values are not redacted",
+			input:    "abc",
+			expected: "abc",
+		},
+		{
+			name:     "No secrets",
+			input:    "expected status code 200, got 500",
+			expected: "expected status code 200, got 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.Redact(tt.input); got != tt.expected {
+				t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSecretStore_Nil(t *testing.T) {
+	var store *SecretStore
+	store.Add("s3cret")
+
+	if got := store.Redact("s3cret"); got != "s3cret" {
+		t.Errorf("expected nil store to redact nothing, got %q", got)
+	}
+}
+
+func TestSecretStore_RedactVariables(t *testing.T) {
+	store := NewSecretStore()
+	variables := map[string]Variable{
+		"api_token": {Type: "string", Value: "tok-12345", Secret: true},
+		"header":    {Type: "string", Value: "Bearer tok-12345"},
+		"user":      {Type: "string", Value: "alice"},
+	}
+	store.AddVariables(variables)
+
+	redacted := store.RedactVariables(variables)
+
+	if redacted["api_token"].Value != RedactedValue {
+		t.Errorf("expected secret variable to be redacted, got %q", redacted["api_token"].Value)
+	}
+	if redacted["header"].Value != "Bearer [REDACTED]" {
+		t.Errorf("expected embedded secret to be redacted, got %q", redacted["header"].Value)
+	}
+	if redacted["user"].Value != "alice" {
+		t.Errorf("expected non-secret variable to be unchanged, got %q", redacted["user"].Value)
+	}
+	if variables["api_token"].Value != "tok-12345" {
+		t.Errorf("expected original variables to be unchanged")
+	}
+}
+
+func TestProcessBodyExports_Secrets(t *testing.T) {
+	notSecret := false
+	secret := true
+
+	resp := &easyreq.HttpResponse{
+		Status: 200,
+		Body:   []byte(`{"access_token": "tok-12345", "session": "sess-67890", "id": "user-4242", "code": "code-1111"}`),
+	}
+	suite := &TestSuite{
+		Variables: make(map[string]Variable),
+		Secrets:   NewSecretStore(),
+	}
+	request := &Request{
+		Export: RequestExport{
+			Body: []BodyExport{
+				{Path: "$.access_token", As: "auth"},
+				{Path: "$.session", As: "sid", Secret: &notSecret},
+				{Path: "$.id", As: "user_id"},
+				{Path: "$.code", As: "code", Secret: &secret},
+			},
+		},
+	}
+
+	if err := processBodyExports(request, resp, suite, logging.NewMockLogger()); err != nil {
+		t.Fatalf("processBodyExports returned error: %v", err)
+	}
+
+	expected := map[string]bool{
+		"auth":    true,
+		"sid":     false,
+		"user_id": false,
+		"code":    true,
+	}
+	for name, wantSecret := range expected {
+		if got := suite.Variables[name].Secret; got != wantSecret {
+			t.Errorf("expected %s secret=%v, got %v", name, wantSecret, got)
+		}
+	}
+
+	got := suite.Secrets.Redact("tok-12345 sess-67890 user-4242 code-1111")
+	want := "[REDACTED] sess-67890 user-4242 [REDACTED]"
+	if got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+// Supported request signing types
+const (
+	SigningTypeNone     = "none"
+	SigningTypeAWSSigV4 = "aws_sigv4"
+	SigningTypeHMAC     = "hmac"
+)
+
+// Signing configures how requests are signed. Signatures are computed after interpolation,
+// over the final request. Like auth, it can be set on a test definition, a suite or a
+// request, and is inherited downward unless overridden.
+type Signing struct {
+	// Type is the signing scheme: aws_sigv4, hmac or none
+	Type string `yaml:"type" json:"type"`
+	// Region is the AWS region for aws_sigv4
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+	// Service is the AWS service signing name for aws_sigv4, e.g. execute-api
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+	// AccessKeyID is the AWS access key ID for aws_sigv4
+	AccessKeyID string `yaml:"access_key_id,omitempty" json:"access_key_id,omitempty"`
+	// SecretAccessKey is the AWS secret access key for aws_sigv4
+	SecretAccessKey string `yaml:"secret_access_key,omitempty" json:"secret_access_key,omitempty"`
+	// SessionToken is the AWS session token for temporary credentials
+	SessionToken string `yaml:"session_token,omitempty" json:"session_token,omitempty"`
+	// Key is the shared secret for hmac
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+	// Algorithm is the hmac hash: sha256 (default), sha1 or sha512
+	Algorithm string `yaml:"algorithm,omitempty" json:"algorithm,omitempty"`
+	// Header is the header the hmac signature is sent in. Defaults to X-Signature.
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+	// Prefix is prepended to the hmac signature, e.g. "HMAC "
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	// Template is the hmac string to sign, see easyreq.HMACSigner
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+	// DateHeader is the header holding the request date for hmac. Defaults to X-Date.
+	DateHeader string `yaml:"date_header,omitempty" json:"date_header,omitempty"`
+	// DateFormat is the Go time layout of the date header. Defaults to the HTTP date format.
+	DateFormat string `yaml:"date_format,omitempty" json:"date_format,omitempty"`
+	// Encoding of the hmac signature: hex (default) or base64
+	Encoding string `yaml:"encoding,omitempty" json:"encoding,omitempty"`
+}
+
+// Validate checks that the fields required by the signing type are set
+func (s *Signing) Validate() error {
+	switch strings.ToLower(s.Type) {
+	case SigningTypeNone:
+	case SigningTypeAWSSigV4:
+		if s.Region == "" || s.Service == "" {
+			return fmt.Errorf("aws_sigv4 signing requires a region and service")
+		}
+		if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+			return fmt.Errorf("aws_sigv4 signing requires access_key_id and secret_access_key")
+		}
+	case SigningTypeHMAC:
+		if s.Key == "" {
+			return fmt.Errorf("hmac signing requires a key")
+		}
+	default:
+		return fmt.Errorf("unsupported signing type: %s", s.Type)
+	}
+	return nil
+}
+
+// fields returns the signing fields that support interpolation
+func (s *Signing) fields() []configField {
+	return []configField{
+		{"region", &s.Region},
+		{"service", &s.Service},
+		{"access_key_id", &s.AccessKeyID},
+		{"secret_access_key", &s.SecretAccessKey},
+		{"session_token", &s.SessionToken},
+		{"key", &s.Key},
+		{"algorithm", &s.Algorithm},
+		{"header", &s.Header},
+		{"prefix", &s.Prefix},
+		{"template", &s.Template},
+		{"date_header", &s.DateHeader},
+		{"date_format", &s.DateFormat},
+		{"encoding", &s.Encoding},
+	}
+}
+
+// interpolate interpolates the fields of the signing configuration in place
+func (s *Signing) interpolate(in *interpolation) error {
+	for _, field := range s.fields() {
+		interpolated, err := in.value(*field.value, "request.sign."+field.name)
+		if err != nil {
+			return fmt.Errorf("error interpolating sign %s: %w", field.name, err)
+		}
+		*field.value = interpolated
+	}
+	return nil
+}
+
+// credentials returns the secret values of the signing configuration
+func (s *Signing) credentials() []string {
+	return []string{s.SecretAccessKey, s.SessionToken, s.Key}
+}
+
+// signer returns the easyreq signer for the configuration
+func (s *Signing) signer() easyreq.RequestSigner {
+	switch strings.ToLower(s.Type) {
+	case SigningTypeAWSSigV4:
+		return &easyreq.AWSSigV4Signer{
+			AccessKeyID:     s.AccessKeyID,
+			SecretAccessKey: s.SecretAccessKey,
+			SessionToken:    s.SessionToken,
+			Region:          s.Region,
+			Service:         s.Service,
+		}
+	case SigningTypeHMAC:
+		return &easyreq.HMACSigner{
+			Key:        s.Key,
+			Algorithm:  s.Algorithm,
+			Header:     s.Header,
+			Prefix:     s.Prefix,
+			Template:   s.Template,
+			DateHeader: s.DateHeader,
+			DateFormat: s.DateFormat,
+			Encoding:   s.Encoding,
+		}
+	}
+	return nil
+}
+
+// effectiveSigning returns the signing configuration for a request: the request's own, or
+// the inherited one. The result is a copy that can be interpolated without changing the
+// test definition.
+func effectiveSigning(requestSigning *Signing, inherited *Signing) *Signing {
+	signing := requestSigning
+	if signing == nil {
+		signing = inherited
+	}
+	if signing == nil || strings.EqualFold(signing.Type, SigningTypeNone) {
+		return nil
+	}
+
+	copied := *signing
+	return &copied
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestExecCase_Signing(t *testing.T) {
+	// The server verifies an HMAC over the method, path and hash of the body it received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		mac := hmac.New(sha256.New, []byte("shared-secret"))
+		mac.Write([]byte(r.Method + "|" + r.URL.Path + "|" + hex.EncodeToString(sum[:])))
+		if r.Header.Get("X-Signature") != "v1="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if string(body) != `{"name":"widget"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{
+		Sign: &Signing{
+			Type:     "hmac",
+			Key:      "${hmac_key}",
+			Prefix:   "v1=",
+			Template: "{method}|{path}|{body_sha256}",
+		},
+		Variables: map[string]Variable{
+			"hmac_key": {Type: "string", Value: "shared-secret", Secret: true},
+			"name":     {Type: "string", Value: "widget"},
+		},
+		Secrets: NewSecretStore(),
+	}
+	testCase := &TestCase{
+		Title: "signed",
+		Request: Request{
+			Method:     "POST",
+			URL:        server.URL + "/orders",
+			Body:       RequestBody{Type: "json", Data: map[string]interface{}{"name": "${name}"}},
+			Assertions: map[string]interface{}{"status": 200},
+		},
+	}
+
+	result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected signature over the interpolated body to be accepted: %v", result.FailureReasons)
+	}
+
+	// Signing errors fail the test case
+	testCase.Request.Sign = &Signing{Type: "hmac", Key: "k", Template: "{body}"}
+	if _, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client); err == nil || !strings.Contains(err.Error(), "placeholder") {
+		t.Errorf("expected unknown placeholder error, got %v", err)
+	}
+
+	// Signing can be disabled per request
+	testCase.Request.Sign = &Signing{Type: "none"}
+	result, err = suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed {
+		t.Error("expected unsigned request to be rejected")
+	}
+}
+
+func TestSigning_Validate(t *testing.T) {
+	tests := []struct {
+		signing   Signing
+		expectErr bool
+	}{
+		{Signing{Type: "aws_sigv4", Region: "us-east-1", Service: "execute-api", AccessKeyID: "a", SecretAccessKey: "b"}, false},
+		{Signing{Type: "aws_sigv4", Region: "us-east-1"}, true},
+		{Signing{Type: "hmac", Key: "k"}, false},
+		{Signing{Type: "hmac"}, true},
+		{Signing{Type: "none"}, false},
+		{Signing{Type: "rsa"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.signing.Validate(); (err != nil) != tt.expectErr {
+			t.Errorf("Validate(%+v) error = %v, expectErr %v", tt.signing, err, tt.expectErr)
+		}
+	}
+}
+
+func TestSigning_Interpolate(t *testing.T) {
+	signing := &Signing{
+		Type:       "hmac",
+		Key:        "${key}",
+		Algorithm:  "${algorithm}",
+		DateFormat: "${date_format}",
+		Encoding:   "${encoding}",
+	}
+	variables := map[string]Variable{
+		"key":         {Type: "string", Value: "secret"},
+		"algorithm":   {Type: "string", Value: "sha512"},
+		"date_format": {Type: "string", Value: "2006-01-02"},
+		"encoding":    {Type: "string", Value: "base64"},
+	}
+
+	if err := signing.interpolate(&interpolation{ip: &Interpolator{}, variables: variables}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signing.Key != "secret" || signing.Algorithm != "sha512" || signing.DateFormat != "2006-01-02" || signing.Encoding != "base64" {
+		t.Errorf("expected every field to be interpolated, got %+v", signing)
+	}
+	if err := defaultInterpolator.InterpolateRequestStrict(&Request{Sign: &Signing{Type: "hmac", Key: "k", Encoding: "${missing}"}}, nil); err == nil {
+		t.Errorf("expected an unresolved encoding to be reported")
+	}
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestExecCase_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{
+		Variables: map[string]Variable{"admin_socket": {Type: "string", Value: socket}},
+		Secrets:   NewSecretStore(),
+	}
+
+	requests := []Request{
+		{Method: "GET", URL: "unix://${admin_socket}:/status", Assertions: map[string]interface{}{"body": map[string]interface{}{"$.path": "/status"}}},
+		{Method: "GET", URL: "/health", Socket: "${admin_socket}", Assertions: map[string]interface{}{"body": map[string]interface{}{"$.path": "/health"}}},
+	}
+	for _, request := range requests {
+		result, err := suite.ExecCase(context.Background(), &TestCase{Title: request.URL, Request: request}, logging.NewMockLogger(), client)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Passed {
+			t.Errorf("expected %s to be sent over the socket: %v", request.URL, result.FailureReasons)
+		}
+	}
+}
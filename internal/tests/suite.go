@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -14,7 +18,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func (suite *TestSuite) Run(logger logging.Logger, client easyreq.HttpClient) (TestSuiteResult, error) {
+// Run executes the suite's test cases. Test cases that have not completed when ctx is done
+// are reported as cancelled.
+func (suite *TestSuite) Run(ctx context.Context, logger logging.Logger, client easyreq.HttpClient) (TestSuiteResult, error) {
 	result := TestSuiteResult{
 		Cases: make(map[string]TestCaseResult, len(suite.Cases)),
 	}
@@ -58,6 +64,8 @@ func (suite *TestSuite) Run(logger logging.Logger, client easyreq.HttpClient) (T
 			wg.Add(1)
 			// Create a local copy to avoid issues with the loop variable
 			testCase := c
+			// Give each test case its own random source so generated values do not depend on scheduling
+			interpolator := suite.interpolator().Fork()
 
 			go func() {
 				defer wg.Done()
@@ -74,17 +82,13 @@ func (suite *TestSuite) Run(logger logging.Logger, client easyreq.HttpClient) (T
 				// Create a local suite copy with copied variables
 				localSuite := *suite
 				localSuite.Variables = testVars
+				localSuite.Interpolator = interpolator
 
 				// Run the test case
-				testCaseResult, err := localSuite.ExecCase(&testCase, logger, client)
+				testCaseResult := localSuite.runCase(ctx, &testCase, logger, client)
 
 				mutex.Lock()
-				if err != nil {
-					logger.Error("Error executing test case", zap.String("title", testCase.Title), zap.Error(err))
-					result.Cases[testCase.Title] = TestCaseResult{Passed: false}
-				} else {
-					result.Cases[testCase.Title] = testCaseResult
-				}
+				result.Cases[testCase.Title] = testCaseResult
 				mutex.Unlock()
 
 				// Send back any variables that were created/modified
@@ -107,20 +111,83 @@ func (suite *TestSuite) Run(logger logging.Logger, client easyreq.HttpClient) (T
 		for _, c := range suite.Cases {
 			logger.Debug("Running test case", zap.String("title", c.Title))
 			// Run the test case
-			testCaseResult, err := suite.ExecCase(&c, logger, client)
-			if err != nil {
-				logger.Error("Error executing test case", zap.String("title", c.Title), zap.Error(err))
-				result.Cases[c.Title] = TestCaseResult{Passed: false}
-			} else {
-				result.Cases[c.Title] = testCaseResult
-			}
+			result.Cases[c.Title] = suite.runCase(ctx, &c, logger, client)
 		}
 	}
 
 	return result, nil
 }
 
-func (suite *TestSuite) ExecCase(testcase *TestCase, logger logging.Logger, client easyreq.HttpClient) (TestCaseResult, error) {
+// strictVariables reports whether unresolved variable references should fail a test case.
+// Strict mode is enabled unless the suite configuration sets strict to false.
+func (suite *TestSuite) strictVariables() bool {
+	if val, ok := suite.Config["strict"]; ok {
+		if boolVal, ok := val.(bool); ok {
+			return boolVal
+		}
+	}
+	return true
+}
+
+// interpolator returns the Interpolator configured for the suite, or the default one
+func (suite *TestSuite) interpolator() *Interpolator {
+	if suite.Interpolator != nil {
+		return suite.Interpolator
+	}
+	return defaultInterpolator
+}
+
+// tokens returns the OAuth2 token cache for the suite, or the default one
+func (suite *TestSuite) tokens() *TokenCache {
+	if suite.Tokens != nil {
+		return suite.Tokens
+	}
+	return defaultTokenCache
+}
+
+// erroredCaseResult builds the result for a test case that could not be executed
+func (suite *TestSuite) erroredCaseResult(err error) TestCaseResult {
+	var timeoutErr *easyreq.TimeoutError
+	return TestCaseResult{
+		Passed:         false,
+		Errored:        true,
+		TimedOut:       errors.As(err, &timeoutErr),
+		FailureReasons: []string{suite.Secrets.Redact(err.Error())},
+	}
+}
+
+// cancelledCaseResult builds the result for a test case that was stopped or never
+// started because ctx is done
+func (suite *TestSuite) cancelledCaseResult(ctx context.Context) TestCaseResult {
+	return TestCaseResult{
+		Passed:         false,
+		Cancelled:      true,
+		FailureReasons: []string{fmt.Sprintf("cancelled: %v", context.Cause(ctx))},
+	}
+}
+
+// runCase executes a test case and converts errors into an errored result. Test cases are
+// reported as cancelled when ctx is done before or while they run.
+func (suite *TestSuite) runCase(ctx context.Context, testcase *TestCase, logger logging.Logger, client easyreq.HttpClient) TestCaseResult {
+	if ctx.Err() != nil {
+		return suite.cancelledCaseResult(ctx)
+	}
+
+	result, err := suite.ExecCase(ctx, testcase, logger, client)
+	if err != nil {
+		if ctx.Err() != nil {
+			logger.Debug("Test case cancelled", zap.String("title", testcase.Title), zap.Error(err))
+			return suite.cancelledCaseResult(ctx)
+		}
+		logger.Error("Error executing test case", zap.String("title", testcase.Title), zap.Error(err))
+		return suite.erroredCaseResult(err)
+	}
+	return result
+}
+
+// ExecCase executes a test case and validates the response against its assertions.
+// The request is cancelled when ctx is done.
+func (suite *TestSuite) ExecCase(ctx context.Context, testcase *TestCase, logger logging.Logger, client easyreq.HttpClient) (TestCaseResult, error) {
 	startTime := time.Now()
 
 	// Create a copy of the request to apply variable interpolation
@@ -130,68 +197,74 @@ func (suite *TestSuite) ExecCase(testcase *TestCase, logger logging.Logger, clie
 	// We need to pass these variables to the suite when executing tests
 	variables := suite.Variables
 
-	// Apply variable interpolation to the request
-	if err := InterpolateRequest(&request, variables); err != nil {
+	// Requests inherit the suite's auth and signing unless they set their own
+	request.Auth = effectiveAuth(request.Auth, suite.Auth)
+	request.Sign = effectiveSigning(request.Sign, suite.Sign)
+
+	// The base URL, headers, query parameters and body type come from the defaults unless
+	// the request sets them
+	suite.Defaults.applyDefaults(&request)
+
+	// Apply variable interpolation to the request. In strict mode, refuse to send requests
+	// with references that could not be resolved.
+	interpolate := suite.interpolator().InterpolateRequest
+	if suite.strictVariables() {
+		interpolate = suite.interpolator().InterpolateRequestStrict
+	}
+	if err := interpolate(&request, variables); err != nil {
+		var undefinedErr *UndefinedVariableError
+		if errors.As(err, &undefinedErr) {
+			logger.Debug("Unresolved variable reference in request", zap.Error(err))
+			return TestCaseResult{}, err
+		}
 		logger.Debug("Error interpolating variables in request", zap.Error(err))
 		return TestCaseResult{}, fmt.Errorf("error interpolating variables: %w", err)
 	}
 
-	// Convert request headers to map format
-	headers := make(map[string]interface{})
-	for _, h := range request.Headers {
-		headers[h.Key] = h.Value
+	if request.Auth != nil {
+		suite.Secrets.Add(request.Auth.credentials()...)
+	}
+	if request.Sign != nil {
+		suite.Secrets.Add(request.Sign.credentials()...)
 	}
 
-	// Prepare request params
-	params := easyreq.RequestParams{
-		Headers: headers,
+	timeout, err := ParseTimeout(effectiveTimeout(testcase.Timeout, suite.Timeout))
+	if err != nil {
+		return TestCaseResult{}, err
 	}
 
-	var resp *easyreq.HttpResponse
-	var err error
+	// OAuth2 access tokens are fetched once per run and shared between requests
+	var token string
+	if request.Auth.isOAuth2() {
+		token, err = suite.tokens().Token(ctx, request.Auth, client)
+		if err != nil {
+			return TestCaseResult{}, fmt.Errorf("error getting OAuth2 token: %w", err)
+		}
+		suite.Secrets.Add(token)
+	}
 
 	logger.Debug("Executing request", zap.String("method", request.Method), zap.String("url", request.URL))
 
-	// Execute request based on method
-	switch strings.ToUpper(request.Method) {
-	case "GET":
-		resp, err = client.Get(request.URL, params)
-	case "POST":
-		var body interface{}
-		if request.Body.Data != nil {
-			if request.Body.Type == "json" {
-				// Handle JSON body string data
-				if strData, ok := request.Body.Data.(string); ok {
-					var jsonBody interface{}
-					if err := json.Unmarshal([]byte(strData), &jsonBody); err == nil {
-						body = jsonBody
-					} else {
-						body = strData // Use as string if not valid JSON
-					}
-				} else {
-					body = request.Body.Data
-				}
-			} else {
-				body = request.Body.Data
-			}
-		}
-		resp, err = client.Post(request.URL, body, params)
-	case "PUT":
-		resp, err = client.Put(request.URL, request.Body.Data, params)
-	case "DELETE":
-		resp, err = client.Delete(request.URL, params)
-	case "OPTIONS":
-		resp, err = client.Options(request.URL, params)
-	case "HEAD":
-		resp, err = client.Head(request.URL, params)
-	case "PATCH":
-		resp, err = client.Patch(request.URL, request.Body.Data, params)
-	default:
-		return TestCaseResult{}, fmt.Errorf("unsupported HTTP method: %s", request.Method)
+	resp, err := suite.sendRequest(ctx, &request, client, token, timeout)
+	if err != nil {
+		return TestCaseResult{}, err
 	}
 
-	if err != nil {
-		return TestCaseResult{}, fmt.Errorf("error executing request: %v", err)
+	// A rejected OAuth2 token may have been revoked or expired early; get a new one and retry once
+	if resp != nil && resp.Status == http.StatusUnauthorized && request.Auth.isOAuth2() {
+		logger.Debug("OAuth2 token rejected, requesting a new token")
+		suite.tokens().Invalidate(request.Auth, token)
+
+		token, err = suite.tokens().Token(ctx, request.Auth, client)
+		if err != nil {
+			return TestCaseResult{}, fmt.Errorf("error getting OAuth2 token: %w", err)
+		}
+		suite.Secrets.Add(token)
+
+		resp, err = suite.sendRequest(ctx, &request, client, token, timeout)
+		if err != nil {
+			return TestCaseResult{}, err
+		}
 	}
 
 	// Process response body exports if they exist and we have exports defined
@@ -218,10 +291,106 @@ func (suite *TestSuite) ExecCase(testcase *TestCase, logger logging.Logger, clie
 	return TestCaseResult{
 		Passed:         passed,
 		Timing:         elapsedTime,
-		FailureReasons: failureReasons,
+		FailureReasons: suite.Secrets.RedactAll(failureReasons),
 	}, err
 }
 
+// sendRequest sends an interpolated request with the client. token is the OAuth2 access
+// token for requests that use oauth2 auth, and a zero timeout uses the client's timeout.
+func (suite *TestSuite) sendRequest(ctx context.Context, request *Request, client easyreq.HttpClient, token string, timeout time.Duration) (*easyreq.HttpResponse, error) {
+	// Prepare request params
+	params := easyreq.RequestParams{
+		Headers: make(map[string]interface{}),
+		Query:   make(map[string]interface{}, len(request.Query)),
+		Timeout: timeout,
+		Jar:     suite.Jar,
+		Socket:  request.Socket,
+		BaseUrl: request.BaseURL,
+	}
+
+	for k, v := range request.Query {
+		params.Query[k] = v
+	}
+
+	maxRedirects, err := ParseFollowRedirects(request.FollowRedirects)
+	if err != nil {
+		return nil, err
+	}
+	params.MaxRedirects = maxRedirects
+
+	if request.Auth != nil {
+		request.Auth.apply(&params, token)
+	}
+
+	// Convert request headers to map format. Headers set on the request take precedence
+	// over headers added by auth, e.g. to test an invalid Authorization header. Repeated
+	// headers are kept in order, and removed headers are not sent at all.
+	requestHeaders := make(map[string]string)
+	for _, h := range request.Headers {
+		name := strings.ToLower(h.Key)
+		if key, ok := requestHeaders[name]; ok && !h.Remove {
+			values, ok := params.Headers[key].([]string)
+			if !ok {
+				values = []string{fmt.Sprintf("%v", params.Headers[key])}
+			}
+			params.Headers[key] = append(values, h.Value)
+			continue
+		}
+
+		for k := range params.Headers {
+			if strings.EqualFold(k, h.Key) {
+				delete(params.Headers, k)
+			}
+		}
+		if h.Remove {
+			params.RemoveHeaders = append(params.RemoveHeaders, h.Key)
+			delete(requestHeaders, name)
+			continue
+		}
+		// A header set after it was removed is sent
+		params.RemoveHeaders = slices.DeleteFunc(params.RemoveHeaders, func(k string) bool { return strings.EqualFold(k, h.Key) })
+		params.Headers[h.Key] = h.Value
+		requestHeaders[name] = h.Key
+	}
+
+	// Requests are signed by the client once they are built
+	if request.Sign != nil {
+		params.Signer = request.Sign.signer()
+	}
+
+	// Every method is sent the same way, with its body if it has one. Methods are
+	// uppercased, so "get" and "GET" are the same.
+	method := strings.ToUpper(strings.TrimSpace(request.Method))
+	if method == "" {
+		return nil, fmt.Errorf("request method is required")
+	}
+	resp, err := client.Do(ctx, easyreq.NewRequest(method, request.URL, requestBody(request.Body), params))
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// requestBody returns the body to send for a request body, or nil without one. JSON bodies
+// given as a string are sent as the JSON they contain, or as a string when they are not
+// valid JSON.
+func requestBody(body RequestBody) interface{} {
+	if body.Data == nil {
+		return nil
+	}
+
+	if strData, ok := body.Data.(string); ok && body.Type == "json" {
+		var jsonBody interface{}
+		if err := json.Unmarshal([]byte(strData), &jsonBody); err == nil {
+			return jsonBody
+		}
+		return strData // Use as string if not valid JSON
+	}
+
+	return body.Data
+}
+
 // validateWithAssertions checks if the response matches the assertions using the reqassert package
 // It returns:
 // - a boolean indicating if all assertions passed
@@ -262,6 +431,16 @@ func validateWithAssertions(resp *easyreq.HttpResponse, assertionsData map[strin
 		logger.Error("Failed to prepare assertion context", zap.Error(err))
 		return false, nil, err
 	}
+	ctx.TLS = assertionTLSState(resp.TLS)
+	ctx.URL = resp.URL
+	ctx.Protocol = resp.Protocol
+
+	// Cookies set by redirects come first, so the response's own cookies take precedence
+	for _, redirect := range resp.Redirects {
+		ctx.Redirects = append(ctx.Redirects, reqassert.Redirect{Status: redirect.Status, URL: redirect.URL, Location: redirect.Location})
+		ctx.Cookies = append(ctx.Cookies, redirect.Cookies...)
+	}
+	ctx.Cookies = append(ctx.Cookies, (&http.Response{Header: resp.Headers}).Cookies()...)
 
 	// Validate all assertions
 	validationErrors := builder.ValidateAll(assertions, ctx)
@@ -275,6 +454,24 @@ func validateWithAssertions(resp *easyreq.HttpResponse, assertionsData map[strin
 	return len(validationErrors) == 0, validationErrors, nil
 }
 
+// assertionTLSState converts the response's TLS details for TLS assertions, using the
+// server's leaf certificate
+func assertionTLSState(info *easyreq.TLSInfo) *reqassert.TLSState {
+	if info == nil {
+		return nil
+	}
+
+	state := &reqassert.TLSState{Version: info.Version}
+	if len(info.PeerCertificates) > 0 {
+		leaf := info.PeerCertificates[0]
+		state.Subject = leaf.Subject
+		state.Issuer = leaf.Issuer
+		state.DNSNames = leaf.DNSNames
+		state.NotAfter = leaf.NotAfter
+	}
+	return state
+}
+
 // processBodyExports extracts values from the response body based on JSONPath expressions
 // and adds them to the suite variables for use in subsequent test cases
 func processBodyExports(request *Request, resp *easyreq.HttpResponse, suite *TestSuite, logger logging.Logger) error {
@@ -340,7 +537,6 @@ func processBodyExports(request *Request, resp *easyreq.HttpResponse, suite *Tes
 			if err != nil {
 				logger.Warn("Error marshaling complex value to JSON",
 					zap.String("path", export.Path),
-					zap.Any("value", v),
 					zap.Error(err))
 				strValue = fmt.Sprintf("%v", v)
 			} else {
@@ -348,16 +544,39 @@ func processBodyExports(request *Request, resp *easyreq.HttpResponse, suite *Tes
 			}
 		}
 
+		// Exports are secret when marked so, or when the field or variable name looks sensitive
+		secret := IsSensitiveName(export.As) || IsSensitiveName(lastPathSegment(export.Path))
+		if export.Secret != nil {
+			secret = *export.Secret
+		}
+
 		// Store the extracted value as a variable
 		suite.Variables[export.As] = Variable{
-			Type:  "string",
-			Value: strValue,
+			Type:   "string",
+			Value:  strValue,
+			Secret: secret,
+		}
+
+		loggedValue := strValue
+		if secret {
+			suite.Secrets.Add(strValue)
+			loggedValue = RedactedValue
 		}
 
 		logger.Debug("Exported response value to variable",
 			zap.String("variable", export.As),
-			zap.String("value", strValue))
+			zap.String("value", loggedValue),
+			zap.Bool("secret", secret))
 	}
 
 	return nil
 }
+
+// lastPathSegment returns the last field name of a JSONPath expression, e.g. "token" for "$.data.token"
+func lastPathSegment(path string) string {
+	path = strings.TrimRight(path, "]")
+	if idx := strings.LastIndexAny(path, ".["); idx >= 0 {
+		return strings.Trim(path[idx+1:], "'\"")
+	}
+	return path
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestExecCase_Methods(t *testing.T) {
+	// The server echoes the method and body it received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Method", r.Method)
+		if len(body) == 0 {
+			body = []byte("null")
+		}
+		w.Write([]byte(`{"body":` + string(body) + `}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		method     string
+		body       RequestBody
+		assertions map[string]interface{}
+	}{
+		{
+			method:     "PUT",
+			body:       RequestBody{Type: "json", Data: `{"name": "${name}"}`},
+			assertions: map[string]interface{}{"body": map[string]interface{}{"$.body.name": "widget"}},
+		},
+		{
+			method:     "PATCH",
+			body:       RequestBody{Type: "json", Data: `{"count": 2}`},
+			assertions: map[string]interface{}{"body": map[string]interface{}{"$.body.count": 2.0}},
+		},
+		{
+			method:     "DELETE",
+			body:       RequestBody{Type: "json", Data: map[string]interface{}{"ids": []interface{}{1, 2}}},
+			assertions: map[string]interface{}{"body": map[string]interface{}{"$.body.ids[1]": 2.0}},
+		},
+		{
+			method:     "propfind",
+			body:       RequestBody{Type: "json", Data: `{"depth": 1}`},
+			assertions: map[string]interface{}{"headers": map[string]interface{}{"X-Method": "PROPFIND"}, "body": map[string]interface{}{"$.body.depth": 1.0}},
+		},
+		{
+			method:     "PURGE",
+			assertions: map[string]interface{}{"headers": map[string]interface{}{"X-Method": "PURGE"}},
+		},
+	}
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{
+		Variables: map[string]Variable{"name": {Type: "string", Value: "widget"}},
+		Secrets:   NewSecretStore(),
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			tt.assertions["status"] = 200
+			testCase := &TestCase{
+				Title:   tt.method,
+				Request: Request{Method: tt.method, URL: server.URL, Body: tt.body, Assertions: tt.assertions},
+			}
+
+			result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Passed {
+				t.Errorf("expected %s to be sent with its body: %v", tt.method, result.FailureReasons)
+			}
+		})
+	}
+}
+
+func TestExecCase_MissingMethod(t *testing.T) {
+	client := easyreq.NewHttpClientMock()
+	suite := &TestSuite{Secrets: NewSecretStore()}
+	testCase := &TestCase{Title: "no method", Request: Request{URL: "https://api.example.com"}}
+
+	if _, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client); err == nil {
+		t.Errorf("expected an error for a request without a method")
+	}
+	if len(client.DoCalls) != 0 || len(client.GetCalls) != 0 {
+		t.Errorf("expected no request to be sent, got %v %v", client.DoCalls, client.GetCalls)
+	}
+
+	definition := &TestDefinition{
+		Name:   "missing method",
+		Suites: []TestSuite{{Name: "suite", Cases: []TestCase{*testCase}}},
+	}
+	if err := definition.Validate(); err == nil {
+		t.Errorf("expected Validate to reject a test case without a method")
+	}
+}
+
+func TestExecCase_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"headers": r.Header, "contentType": r.Header.Get("Content-Type")})
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions().WithUserAgent("httpprobe/1.2.3"))
+	suite := &TestSuite{Secrets: NewSecretStore()}
+	testCase := &TestCase{
+		Title: "headers",
+		Request: Request{
+			Method: "POST",
+			URL:    server.URL,
+			Headers: []RequestHeader{
+				{Key: "Accept", Value: "application/json"},
+				{Key: "accept", Value: "text/plain"},
+				{Key: "Content-Type", Remove: true},
+				{Key: "X-Trace", Remove: true},
+				{Key: "X-Trace", Value: "abc"},
+			},
+			Body: RequestBody{Type: "json", Data: map[string]interface{}{"name": "widget"}},
+			Assertions: map[string]interface{}{
+				"body": map[string]interface{}{
+					"$.headers.Accept[0]":     "application/json",
+					"$.headers.Accept[1]":     "text/plain",
+					"$.headers.User-Agent[0]": "httpprobe/1.2.3",
+					"$.headers.X-Trace[0]":    "abc",
+					"$.contentType":           "",
+				},
+			},
+		},
+	}
+
+	result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected repeated headers in order and removed headers not to be sent: %v", result.FailureReasons)
+	}
+}
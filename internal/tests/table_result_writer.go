@@ -10,7 +10,15 @@ func NewTableResultWriter() *TableResultWriter {
 	return &TableResultWriter{}
 }
 
-func (w *TableResultWriter) Write(results map[string]TestDefinitionExecResult) {
+func (w *TableResultWriter) Write(results map[string]TestDefinitionExecResult, info RunInfo) {
+	if info.Environment != "" {
+		fmt.Printf("Environment: %s\n", info.Environment)
+	}
+	fmt.Printf("Seed: %d\n", info.Seed)
+	if info.Cancelled != "" {
+		fmt.Printf("Run cancelled (%s), results are partial\n", info.Cancelled)
+	}
+
 	// Implement table-based output with failure details
 	fmt.Println("+-----------------+-----------------+--------+------+-------------------+")
 	fmt.Println("| Test Definition | Test Suite      | Test Case           | Result | Failures            |")
@@ -24,7 +32,13 @@ func (w *TableResultWriter) Write(results map[string]TestDefinitionExecResult) {
 			
 			for caseName, caseResult := range suiteResult.Cases {
 				result := "PASS"
-				if !caseResult.Passed {
+				if caseResult.Cancelled {
+					result = "CANCELLED"
+				} else if caseResult.TimedOut {
+					result = "TIMEOUT"
+				} else if caseResult.Errored {
+					result = "ERROR"
+				} else if !caseResult.Passed {
 					result = "FAIL"
 				}
 				
@@ -1,6 +1,12 @@
 package tests
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
 
 type TestDefinition struct {
 	// Path is the path to the test definition file
@@ -19,6 +25,24 @@ type TestDefinition struct {
 	BeforeEach []string `yaml:"before_each" json:"before_each"`
 	// Test definitions to be executed after each test suite in this definition
 	AfterEach []string `yaml:"after_each"`
+	// Auth is the authentication for every request in the definition, unless overridden
+	Auth *Auth `yaml:"auth" json:"auth"`
+	// Sign is the request signing for every request in the definition, unless overridden
+	Sign *Signing `yaml:"sign" json:"sign"`
+	// TLS configures certificate verification and client certificates for the definition's requests
+	TLS *TLSConfig `yaml:"tls" json:"tls"`
+	// Proxy routes the definition's requests through a proxy
+	Proxy *ProxyConfig `yaml:"proxy" json:"proxy"`
+	// Resolve maps "host:port" to the IP address to connect to instead of looking the host up
+	Resolve map[string]string `yaml:"resolve" json:"resolve"`
+	// HTTPVersion is the HTTP version of the definition's requests: 1.1, 2 or h2c
+	HTTPVersion string `yaml:"http_version" json:"http_version"`
+	// Timeout for each request in the definition, e.g. "30s", unless overridden
+	Timeout string `yaml:"timeout" json:"timeout"`
+	// Defaults are request settings for every test case in the definition
+	Defaults *Defaults `yaml:"defaults" json:"defaults"`
+	// Cookies enables a cookie jar for the definition's requests
+	Cookies *CookieConfig `yaml:"cookies" json:"cookies"`
 	// Test suites to be executed
 	Suites []TestSuite `yaml:"suites" json:"suites"`
 }
@@ -26,6 +50,8 @@ type TestDefinition struct {
 type Variable struct {
 	Type  string `yaml:"type" json:"type"`
 	Value string `yaml:"value" json:"value"`
+	// Secret marks the value as sensitive; it is redacted from logs, reports and failure messages
+	Secret bool `yaml:"secret" json:"secret"`
 }
 
 // TestSuite represent a suite of test cases
@@ -38,12 +64,38 @@ type TestSuite struct {
 	Variables map[string]Variable `yaml:"variables" json:"variables"`
 	// Configuration options for the test suite
 	Config map[string]interface{} `yaml:"config" json:"config"`
+	// Auth is the authentication for every request in the suite, unless overridden.
+	// Defaults to the test definition's auth.
+	Auth *Auth `yaml:"auth" json:"auth"`
+	// Sign is the request signing for every request in the suite, unless overridden.
+	// Defaults to the test definition's signing.
+	Sign *Signing `yaml:"sign" json:"sign"`
+	// Timeout for each request in the suite, unless overridden. Defaults to the test
+	// definition's timeout.
+	Timeout string `yaml:"timeout" json:"timeout"`
+	// Defaults are request settings for every test case in the suite. The runner merges
+	// them with the test definition's defaults.
+	Defaults *Defaults `yaml:"defaults" json:"defaults"`
+	// Cookies configures the cookie jar of the suite. Defaults to the test definition's
+	// cookie configuration.
+	Cookies *CookieConfig `yaml:"cookies" json:"cookies"`
+	// Jar stores and sends cookies for the suite's requests. Set by the runner.
+	Jar http.CookieJar `yaml:"-" json:"-"`
+	// Interpolator used to interpolate requests. Set by the runner; defaults to an Interpolator
+	// that resolves relative paths against the working directory.
+	Interpolator *Interpolator `yaml:"-" json:"-"`
+	// Secrets collects secret values to redact from results. Set by the runner.
+	Secrets *SecretStore `yaml:"-" json:"-"`
+	// Tokens caches OAuth2 access tokens for the run. Set by the runner.
+	Tokens *TokenCache `yaml:"-" json:"-"`
 }
 
 // TestCase represent a test case to be executed
 type TestCase struct {
 	// Title of the test case
 	Title string `yaml:"title" json:"title"`
+	// Timeout for the test case's request. Defaults to the suite's timeout.
+	Timeout string `yaml:"timeout" json:"timeout"`
 	// Request is the HTTP request to be made
 	Request Request `yaml:"request" json:"request"`
 }
@@ -52,16 +104,30 @@ type TestCase struct {
 type Request struct {
 	// Method is the HTTP method to be used
 	Method string `yaml:"method" json:"method"`
-	// URL is the URL to be used for the request
+	// URL is the URL to be used for the request. unix:///path/to.sock:/http/path sends the
+	// request over a unix socket.
 	URL string `yaml:"url" json:"url"`
+	// Socket is the path of a unix socket to send the request over
+	Socket string `yaml:"socket" json:"socket"`
+	// Query parameters are added to the URL
+	Query map[string]string `yaml:"query" json:"query"`
 	// Headers are the headers to be used in the request
 	Headers []RequestHeader `yaml:"headers" json:"headers"`
 	// Body is the body to be sent in the request
 	Body RequestBody `yaml:"body" json:"body"`
+	// Auth is the authentication for the request. Defaults to the suite's auth.
+	Auth *Auth `yaml:"auth" json:"auth"`
+	// Sign is the request signing for the request. Defaults to the suite's signing.
+	Sign *Signing `yaml:"sign" json:"sign"`
+	// FollowRedirects is whether redirects are followed: true (default), false, or the
+	// maximum number of redirects to follow
+	FollowRedirects any `yaml:"follow_redirects" json:"follow_redirects"`
 	// Assertions are the assertions to be made on the response
 	Assertions map[string]interface{} `yaml:"assertions" json:"assertions"`
 	// Export is the data to be exported from the response
 	Export RequestExport `yaml:"export" json:"export"`
+	// BaseURL is joined with relative request URLs. Set from the defaults.
+	BaseURL string `yaml:"-" json:"-"`
 }
 
 type RequestBody struct {
@@ -69,9 +135,14 @@ type RequestBody struct {
 	Data any    `yaml:"data" json:"data"`
 }
 
+// RequestHeader is a header of a request. Headers with the same key are sent as repeated
+// headers, in order.
 type RequestHeader struct {
 	Key   string `yaml:"key" json:"key"`
 	Value string `yaml:"value" json:"value"`
+	// Remove stops the header from being sent, including headers added by default such as
+	// Content-Type and User-Agent
+	Remove bool `yaml:"remove,omitempty" json:"remove,omitempty"`
 }
 
 // Legacy assertion types - kept for backward compatibility
@@ -97,6 +168,9 @@ type BodyExport struct {
 	Path string `yaml:"path" json:"path"`
 	// As is the name to be used when exporting the value from the response to the test context variables
 	As string `yaml:"as" json:"as"`
+	// Secret marks the exported value as sensitive. When not set, values exported from
+	// sensitive looking fields such as access_token are marked secret automatically.
+	Secret *bool `yaml:"secret" json:"secret"`
 }
 
 func (def *TestDefinition) Validate() error {
@@ -108,10 +182,103 @@ func (def *TestDefinition) Validate() error {
 		return fmt.Errorf("test definition must have at least one suite")
 	}
 
+	if def.Auth != nil {
+		if err := def.Auth.Validate(); err != nil {
+			return fmt.Errorf("invalid auth: %w", err)
+		}
+	}
+
+	if def.Sign != nil {
+		if err := def.Sign.Validate(); err != nil {
+			return fmt.Errorf("invalid sign: %w", err)
+		}
+	}
+
+	if def.TLS != nil {
+		if err := def.TLS.Validate(); err != nil {
+			return fmt.Errorf("invalid tls: %w", err)
+		}
+	}
+
+	if _, err := ParseTimeout(def.Timeout); err != nil {
+		return err
+	}
+
+	if err := easyreq.ValidateHTTPVersion(def.HTTPVersion); err != nil {
+		return err
+	}
+
+	if def.Defaults != nil {
+		if err := def.Defaults.Validate(); err != nil {
+			return fmt.Errorf("invalid defaults: %w", err)
+		}
+	}
+
+	if def.Cookies != nil {
+		if err := def.Cookies.Validate(); err != nil {
+			return fmt.Errorf("invalid cookies: %w", err)
+		}
+	}
+
 	for _, suite := range def.Suites {
 		if suite.Name == "" {
 			return fmt.Errorf("suite name is required")
 		}
+
+		if suite.Auth != nil {
+			if err := suite.Auth.Validate(); err != nil {
+				return fmt.Errorf("invalid auth in suite %s: %w", suite.Name, err)
+			}
+		}
+
+		if suite.Sign != nil {
+			if err := suite.Sign.Validate(); err != nil {
+				return fmt.Errorf("invalid sign in suite %s: %w", suite.Name, err)
+			}
+		}
+
+		if _, err := ParseTimeout(suite.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout in suite %s: %w", suite.Name, err)
+		}
+
+		if suite.Cookies != nil {
+			if err := suite.Cookies.Validate(); err != nil {
+				return fmt.Errorf("invalid cookies in suite %s: %w", suite.Name, err)
+			}
+		}
+
+		if suite.Defaults != nil {
+			if err := suite.Defaults.Validate(); err != nil {
+				return fmt.Errorf("invalid defaults in suite %s: %w", suite.Name, err)
+			}
+		}
+
+		for _, c := range suite.Cases {
+			if strings.TrimSpace(c.Request.Method) == "" {
+				return fmt.Errorf("method is required in test case %s", c.Title)
+			}
+			if _, err := ParseTimeout(c.Timeout); err != nil {
+				return fmt.Errorf("invalid timeout in test case %s: %w", c.Title, err)
+			}
+			if _, err := ParseFollowRedirects(c.Request.FollowRedirects); err != nil {
+				return fmt.Errorf("invalid follow_redirects in test case %s: %w", c.Title, err)
+			}
+			for _, h := range c.Request.Headers {
+				if h.Remove && h.Value != "" {
+					return fmt.Errorf("header %s in test case %s cannot have both a value and remove", h.Key, c.Title)
+				}
+			}
+			if c.Request.Auth != nil {
+				if err := c.Request.Auth.Validate(); err != nil {
+					return fmt.Errorf("invalid auth in test case %s: %w", c.Title, err)
+				}
+			}
+			if c.Request.Sign != nil {
+				if err := c.Request.Sign.Validate(); err != nil {
+					return fmt.Errorf("invalid sign in test case %s: %w", c.Title, err)
+				}
+			}
+		}
 	}
 
 	return nil
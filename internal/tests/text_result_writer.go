@@ -12,13 +12,21 @@ func NewTextResultWriter() *TextResultWriter {
 	return &TextResultWriter{}
 }
 
-func (w *TextResultWriter) Write(results map[string]TestDefinitionExecResult) {
+func (w *TextResultWriter) Write(results map[string]TestDefinitionExecResult, info RunInfo) {
 	totalPassedSuites := 0
 	testSuiteCount := 0
 	testCaseCount := 0
 	passedTestCaseCount := 0
 	totalTiming := 0.0
 
+	if info.Environment != "" {
+		color.White("Environment: %s\n", info.Environment)
+	}
+	color.White("Seed: %d\n\n", info.Seed)
+	if info.Cancelled != "" {
+		color.Yellow("Run cancelled (%s), results are partial\n\n", info.Cancelled)
+	}
+
 	for defName, defResult := range results {
 		color.Cyan("%s: %s\n", defName, defResult.Path)
 
@@ -35,7 +43,13 @@ func (w *TextResultWriter) Write(results map[string]TestDefinitionExecResult) {
 			for caseName, caseResult := range suiteResult.Cases {
 				status := color.RedString("FAIL")
 
-				if caseResult.Passed {
+				if caseResult.Cancelled {
+					status = color.YellowString("CANCELLED")
+				} else if caseResult.TimedOut {
+					status = color.YellowString("TIMEOUT")
+				} else if caseResult.Errored {
+					status = color.YellowString("ERROR")
+				} else if caseResult.Passed {
 					status = color.GreenString("PASS")
 					passedTestCaseCount++
 				}
@@ -46,7 +60,11 @@ func (w *TextResultWriter) Write(results map[string]TestDefinitionExecResult) {
 				
 				// If the test failed and we have failure reasons, display them
 				if !caseResult.Passed && len(caseResult.FailureReasons) > 0 {
-					fmt.Println("      Failures:")
+					if caseResult.Errored {
+						fmt.Println("      Errors:")
+					} else {
+						fmt.Println("      Failures:")
+					}
 					for _, reason := range caseResult.FailureReasons {
 						fmt.Printf("        - %s\n", reason)
 					}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseTimeout parses a timeout from a test definition. It accepts a Go duration such as
+// "500ms" or "1m30s", or a number of milliseconds. An empty value returns zero, meaning
+// the timeout is inherited.
+func ParseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if ms, err := strconv.Atoi(value); err == nil {
+		if ms < 0 {
+			return 0, fmt.Errorf("timeout must not be negative: %s", value)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q, expected a duration such as 30s", value)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("timeout must not be negative: %s", value)
+	}
+	return timeout, nil
+}
+
+// effectiveTimeout returns the first timeout that is set, or an empty string
+func effectiveTimeout(timeouts ...string) string {
+	for _, timeout := range timeouts {
+		if timeout != "" {
+			return timeout
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		shouldError bool
+	}{
+		{value: "", expected: 0},
+		{value: "250ms", expected: 250 * time.Millisecond},
+		{value: "1m30s", expected: 90 * time.Second},
+		{value: "1500", expected: 1500 * time.Millisecond},
+		{value: "-1s", shouldError: true},
+		{value: "soon", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			timeout, err := ParseTimeout(tt.value)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("expected error: %v, got %v", tt.shouldError, err)
+			}
+			if timeout != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestRun_Timeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{
+		Timeout: "5s",
+		Cases: []TestCase{
+			{
+				Title:   "slow",
+				Timeout: "50ms",
+				Request: Request{Method: "GET", URL: server.URL + "/slow", Assertions: map[string]interface{}{"status": 200}},
+			},
+			{
+				Title:   "fast",
+				Request: Request{Method: "GET", URL: server.URL + "/fast", Assertions: map[string]interface{}{"status": 200}},
+			},
+		},
+		Secrets: NewSecretStore(),
+	}
+
+	result, err := suite.Run(context.Background(), logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slow := result.Cases["slow"]
+	if !slow.TimedOut || slow.Passed {
+		t.Fatalf("expected the slow case to time out, got %+v", slow)
+	}
+	if !strings.Contains(slow.FailureReasons[0], "waiting for response") {
+		t.Errorf("expected the failure to name the phase, got %q", slow.FailureReasons[0])
+	}
+	if !result.Cases["fast"].Passed {
+		t.Errorf("expected the fast case to use the suite timeout and pass: %v", result.Cases["fast"].FailureReasons)
+	}
+
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	requests := make(chan struct{}, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions())
+	suite := &TestSuite{
+		Cases: []TestCase{
+			{Title: "in flight", Request: Request{Method: "GET", URL: server.URL + "/first"}},
+			{Title: "not started", Request: Request{Method: "GET", URL: server.URL + "/second"}},
+		},
+		Secrets: NewSecretStore(),
+	}
+
+	// Cancel the run while the first request is waiting for a response
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		<-requests
+		cancel(fmt.Errorf("interrupted"))
+	}()
+
+	result, err := suite.Run(ctx, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, title := range []string{"in flight", "not started"} {
+		caseResult := result.Cases[title]
+		if !caseResult.Cancelled || caseResult.Passed || caseResult.TimedOut {
+			t.Errorf("expected %q to be cancelled, got %+v", title, caseResult)
+			continue
+		}
+		if !strings.Contains(caseResult.FailureReasons[0], "interrupted") {
+			t.Errorf("expected the cancellation cause in the failure reason, got %q", caseResult.FailureReasons[0])
+		}
+	}
+	if len(requests) != 0 {
+		t.Errorf("expected the second request not to be sent")
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+// TLSConfig configures TLS for the requests of a test definition. Relative file paths are
+// resolved against the directory of the test definition.
+type TLSConfig struct {
+	// CAFile is a PEM bundle of certificate authorities to trust in addition to the system roots
+	CAFile string `yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+	// CertFile is the PEM client certificate for mutual TLS
+	CertFile string `yaml:"cert_file,omitempty" json:"cert_file,omitempty"`
+	// KeyFile is the PEM private key of the client certificate
+	KeyFile string `yaml:"key_file,omitempty" json:"key_file,omitempty"`
+	// ServerName overrides the host name used to verify the server certificate and for SNI
+	ServerName string `yaml:"server_name,omitempty" json:"server_name,omitempty"`
+	// MinVersion is the minimum TLS version: 1.0, 1.1, 1.2 or 1.3
+	MinVersion string `yaml:"min_version,omitempty" json:"min_version,omitempty"`
+	// InsecureSkipVerify disables verification of the server certificate
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" json:"insecure_skip_verify,omitempty"`
+	// Pins are base64 SHA-256 hashes of acceptable server public keys, e.g. "sha256/AbC...="
+	Pins []string `yaml:"pins,omitempty" json:"pins,omitempty"`
+}
+
+// Validate checks the TLS configuration
+func (c *TLSConfig) Validate() error {
+	switch strings.TrimPrefix(c.MinVersion, "TLS") {
+	case "", "1.0", "1.1", "1.2", "1.3":
+	default:
+		return fmt.Errorf("unsupported min_version: %s", c.MinVersion)
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+
+	return nil
+}
+
+// fields returns the TLS fields that support interpolation
+func (c *TLSConfig) fields() []configField {
+	return []configField{
+		{"ca_file", &c.CAFile},
+		{"cert_file", &c.CertFile},
+		{"key_file", &c.KeyFile},
+		{"server_name", &c.ServerName},
+	}
+}
+
+// Options interpolates the configuration with the variables and returns the easyreq TLS
+// options for it, along with the first interpolation error.
+func (c *TLSConfig) Options(ip *Interpolator, variables map[string]Variable) (*easyreq.TLSOptions, error) {
+	values := ip.lenient(variables)
+	resolved := *c
+	for _, field := range resolved.fields() {
+		*field.value = values.interpolate("tls "+field.name, *field.value)
+	}
+
+	return &easyreq.TLSOptions{
+		CAFile:             ip.resolvePath(resolved.CAFile),
+		CertFile:           ip.resolvePath(resolved.CertFile),
+		KeyFile:            ip.resolvePath(resolved.KeyFile),
+		ServerName:         resolved.ServerName,
+		MinVersion:         resolved.MinVersion,
+		InsecureSkipVerify: resolved.InsecureSkipVerify,
+		Pins:               resolved.Pins,
+	}, values.err
+}
+
+// resolvePath resolves a relative path against the interpolator's base directory
+func (ip *Interpolator) resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(ip.baseDir(), path)
+}
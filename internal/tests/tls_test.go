@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
+)
+
+func TestTLSConfigOptions(t *testing.T) {
+	config := &TLSConfig{
+		CAFile:     "certs/${env_name}-ca.pem",
+		CertFile:   "/etc/httpprobe/client.pem",
+		KeyFile:    "/etc/httpprobe/client-key.pem",
+		ServerName: "${host}",
+		MinVersion: "1.2",
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	ip := &Interpolator{BaseDir: filepath.Join("testdata", "api")}
+	variables := map[string]Variable{
+		"env_name": {Type: "string", Value: "staging"},
+		"host":     {Type: "string", Value: "api.internal"},
+	}
+
+	options, err := config.Options(ip, variables)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := filepath.Join("testdata", "api", "certs", "staging-ca.pem"); options.CAFile != expected {
+		t.Errorf("expected CA file %s, got %s", expected, options.CAFile)
+	}
+	if options.CertFile != "/etc/httpprobe/client.pem" {
+		t.Errorf("expected absolute cert file to be kept, got %s", options.CertFile)
+	}
+	if options.ServerName != "api.internal" || options.MinVersion != "1.2" {
+		t.Errorf("unexpected options: %+v", options)
+	}
+
+	if err := (&TLSConfig{CertFile: "client.pem"}).Validate(); err == nil {
+		t.Errorf("expected an error for a cert_file without a key_file")
+	}
+	if err := (&TLSConfig{MinVersion: "1.4"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unsupported min_version")
+	}
+}
+
+func TestExecCase_TLSAssertions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := easyreq.New(easyreq.NewOptions().WithTLS(&easyreq.TLSOptions{InsecureSkipVerify: true}))
+	suite := &TestSuite{Secrets: NewSecretStore()}
+	testCase := &TestCase{
+		Title: "certificate",
+		Request: Request{
+			Method: "GET",
+			URL:    server.URL,
+			Assertions: map[string]interface{}{
+				"status": 200,
+				"tls": map[string]interface{}{
+					"expires_in_days": 30,
+					"issuer":          "O=Acme Co",
+					"dns_name":        "example.com",
+				},
+			},
+		},
+	}
+
+	result, err := suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected TLS assertions to pass: %v", result.FailureReasons)
+	}
+
+	testCase.Request.Assertions["tls"] = map[string]interface{}{"subject": "CN=api.example.org"}
+	result, err = suite.ExecCase(context.Background(), testCase, logging.NewMockLogger(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed {
+		t.Errorf("expected subject assertion to fail")
+	}
+}
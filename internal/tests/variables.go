@@ -1,10 +1,7 @@
 package tests
 
 import (
-	"bufio"
 	"fmt"
-	"math/rand"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -13,129 +10,235 @@ import (
 	"github.com/google/uuid"
 )
 
-// Define a global random source
-var randomSource = rand.NewSource(time.Now().UnixNano())
-var random = rand.New(randomSource)
+// envVarPattern matches environment variable references: ${env:VAR_NAME} or ${env:VAR_NAME:-default}
+var envVarPattern = regexp.MustCompile(`\$\{env:([^}:]+)(?::-([^}]*))?\}`)
+
+// variableRefPattern matches variable references: ${name} or ${name:-default}
+var variableRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.\-]*)(?::-([^}]*))?\}`)
+
+// funcCallPattern matches the start of a function call: ${name(
+var funcCallPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.]*)\(`)
+
+// Interpolator evaluates variable references, environment variables and function calls in
+// test definition values. The zero value is ready to use.
+type Interpolator struct {
+	// BaseDir is the directory relative file paths are resolved against, usually the
+	// directory of the test definition being executed
+	BaseDir string
+	// Random is the source of random values for functions and fake data generators.
+	// Defaults to the package-level source.
+	Random *RandomSource
+	// Providers resolves ${provider:reference} references. Defaults to DefaultSecretProviders.
+	Providers SecretProviders
+	// Secrets receives the values returned by secret providers so they are redacted
+	Secrets *SecretStore
+	// Env resolves ${env:...} references. Defaults to the process environment.
+	Env *Environment
+}
+
+// random returns the Interpolator's random source, or the package-level source
+func (ip *Interpolator) random() *RandomSource {
+	if ip != nil && ip.Random != nil {
+		return ip.Random
+	}
+	return random
+}
+
+// baseDir returns the Interpolator's base directory
+func (ip *Interpolator) baseDir() string {
+	if ip == nil {
+		return ""
+	}
+	return ip.BaseDir
+}
 
-// LoadEnvFile loads environment variables from a file
-// Environment variables in the file should be in the format KEY=VALUE
-func LoadEnvFile(filePath string) error {
-	if filePath == "" {
+// env returns the Interpolator's environment, which may be nil
+func (ip *Interpolator) env() *Environment {
+	if ip == nil {
 		return nil
 	}
+	return ip.Env
+}
 
-	file, err := os.Open(filePath)
-	if err != nil {
-		// If file doesn't exist, just return nil (not an error)
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("error opening env file: %w", err)
+// secrets returns the Interpolator's secret store, which may be nil
+func (ip *Interpolator) secrets() *SecretStore {
+	if ip == nil {
+		return nil
 	}
-	defer file.Close()
+	return ip.Secrets
+}
+
+// Fork returns a copy of the Interpolator with its own random source, seeded from this
+// Interpolator's source. Forking in a fixed order before starting concurrent work keeps the
+// values generated by each unit of work reproducible.
+func (ip *Interpolator) Fork() *Interpolator {
+	fork := *ip
+	fork.Random = NewRandomSource(ip.random().Int63())
+	return &fork
+}
 
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
+// defaultInterpolator is used by the package-level interpolation functions
+var defaultInterpolator = &Interpolator{}
+
+// UndefinedVariableError is returned in strict mode when a request references a variable,
+// environment variable or secret provider that could not be resolved, calls a function
+// that could not be evaluated or contains a malformed reference
+type UndefinedVariableError struct {
+	// Name is the referenced variable name, e.g. "base_url" or "env:API_KEY", the called
+	// function followed by "()", e.g. "sha256()", or a malformed reference, e.g. "${ id }"
+	Name string
+	// Location is the part of the request the reference was found in, e.g. "request.url"
+	Location string
+}
 
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
+func (e *UndefinedVariableError) Error() string {
+	switch {
+	case strings.HasSuffix(e.Name, "()"):
+		return fmt.Sprintf("unresolved function call '%s' in %s", e.Name, e.Location)
+	case strings.HasPrefix(e.Name, "${"):
+		return fmt.Sprintf("invalid reference '%s' in %s", e.Name, e.Location)
+	default:
+		return fmt.Sprintf("undefined variable '%s' in %s", e.Name, e.Location)
+	}
+}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+// InterpolateVariables replaces variable references in the input string
+// with their corresponding values from the variables map
+func InterpolateVariables(input string, variables map[string]Variable) (string, error) {
+	return defaultInterpolator.InterpolateVariables(input, variables)
+}
+
+// InterpolateVariables replaces variable references in the input string
+// with their corresponding values from the variables map
+func (ip *Interpolator) InterpolateVariables(input string, variables map[string]Variable) (string, error) {
+	result, _, err := ip.interpolate(input, variables)
+	return result, err
+}
+
+// interpolate replaces the references in the input and returns the name of the first
+// reference that could not be resolved, or an empty string if all were resolved.
+// References that cannot be resolved, and malformed ones, are left unchanged.
+func (ip *Interpolator) interpolate(input string, variables map[string]Variable) (string, string, error) {
+	if !strings.Contains(input, "${") {
+		return input, "", nil
+	}
 
-		// Split on first equals sign
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid format in env file at line %d: %s", lineNum, line)
+	// References are parsed from the input only. Values are inserted as they are and never
+	// parsed again, so a value that looks like a template, e.g. one exported from a
+	// response, is not evaluated or reported as unresolved.
+	var sb strings.Builder
+	var firstUnresolved string
+	pos := 0
+
+	for {
+		idx := strings.Index(input[pos:], "${")
+		if idx < 0 {
+			sb.WriteString(input[pos:])
+			break
 		}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		start := pos + idx
+		sb.WriteString(input[pos:start])
 
-		// Remove quotes if present
-		if len(value) > 1 && (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
-			(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
-			value = value[1 : len(value)-1]
+		parser := &funcParser{input: input, pos: start}
+		ref, ok := parser.parseRef()
+		if !ok {
+			// Not a valid reference, keep the text and continue after "${"
+			if firstUnresolved == "" {
+				firstUnresolved = malformedRef(input[start:])
+			}
+			sb.WriteString("${")
+			pos = start + 2
+			continue
 		}
 
-		// Set environment variable
-		err := os.Setenv(key, value)
+		value, unresolved, err := ip.evaluateRef(ref, variables)
 		if err != nil {
-			return fmt.Errorf("error setting environment variable %s: %w", key, err)
+			return "", "", err
 		}
+		if unresolved == "" {
+			sb.WriteString(value)
+		} else {
+			// Leave references that cannot be resolved unchanged
+			sb.WriteString(input[start:parser.pos])
+			if firstUnresolved == "" {
+				firstUnresolved = unresolved
+			}
+		}
+		pos = parser.pos
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading env file: %w", err)
-	}
-
-	return nil
+	return sb.String(), firstUnresolved, nil
 }
 
-// InterpolateVariables replaces variable references in the input string
-// with their corresponding values from the variables map
-func InterpolateVariables(input string, variables map[string]Variable) (string, error) {
-	if input == "" {
-		return input, nil
-	}
-
-	result := input
-
-	// First, handle environment variables: ${env:VAR_NAME}
-	envVarPattern := regexp.MustCompile(`\${env:([^}]+)}`)
-	result = envVarPattern.ReplaceAllStringFunc(result, func(match string) string {
-		submatches := envVarPattern.FindStringSubmatch(match)
-		if len(submatches) < 2 {
-			return match
+// evaluateRef returns the value of a reference. unresolved is the name of the reference,
+// or of the reference within a call, that could not be resolved and has no default value.
+func (ip *Interpolator) evaluateRef(ref *templateRef, variables map[string]Variable) (string, string, error) {
+	switch {
+	case ref.call != nil:
+		return ip.callFunction(ref.call, variables)
+	case ref.kind == refEnv:
+		if value := ip.env().Get(ref.name); value != "" {
+			return value, "", nil
 		}
-
-		envName := submatches[1]
-		envValue := os.Getenv(envName)
-		if envValue == "" {
-			// If environment variable doesn't exist, leave the original reference
-			return match
+	case ref.kind == refProvider:
+		return ip.resolveProviderRef(ref)
+	default:
+		if variable, exists := variables[ref.name]; exists {
+			return variable.Value, "", nil
 		}
+	}
+
+	// Fall back to the default value if one was given
+	if ref.hasFallback {
+		return ref.fallback, "", nil
+	}
+	return "", ref.String(), nil
+}
 
-		return envValue
-	})
+// interpolation interpolates the values of a request with the variables and records the
+// first reference that could not be resolved, for strict mode
+type interpolation struct {
+	ip        *Interpolator
+	variables map[string]Variable
+	// unresolved is the first reference that could not be resolved, or nil
+	unresolved *UndefinedVariableError
+}
 
-	// Then, handle regular variables: ${variable_name}
-	for name, variable := range variables {
-		pattern := "${" + name + "}"
-		result = strings.Replace(result, pattern, variable.Value, -1)
+// value interpolates a value found at location in the request
+func (in *interpolation) value(input, location string) (string, error) {
+	result, unresolved, err := in.ip.interpolate(input, in.variables)
+	if err == nil && unresolved != "" && in.unresolved == nil {
+		in.unresolved = &UndefinedVariableError{Name: unresolved, Location: location}
 	}
+	return result, err
+}
 
-	// Finally, handle function calls: ${functionName(arg1,arg2,...)}
-	funcPattern := regexp.MustCompile(`\${([a-zA-Z]+)\(([^)]*)\)}`)
-	result = funcPattern.ReplaceAllStringFunc(result, func(match string) string {
-		submatches := funcPattern.FindStringSubmatch(match)
-		if len(submatches) < 3 {
-			return match
-		}
+// lenientInterpolation interpolates the values of a configuration that is used even when
+// some of them fail to interpolate. Values that fail are kept as they are and the first
+// error is recorded.
+type lenientInterpolation struct {
+	ip        *Interpolator
+	variables map[string]Variable
+	err       error
+}
 
-		funcName := submatches[1]
-		argsStr := submatches[2]
-		args := strings.Split(argsStr, ",")
-
-		// Process different functions
-		switch funcName {
-		case "random":
-			return processRandomFunction(args)
-		case "timestamp":
-			return processTimestampFunction(args)
-		case "now":
-			return processNowFunction()
-		case "uuid":
-			return processUUIDFunction()
-		default:
-			return match // Unknown function, no change
-		}
-	})
+// lenient returns a lenientInterpolation with the variables
+func (ip *Interpolator) lenient(variables map[string]Variable) *lenientInterpolation {
+	return &lenientInterpolation{ip: ip, variables: variables}
+}
 
-	return result, nil
+// interpolate returns the interpolated value, or the value itself if it fails to
+// interpolate. name describes the value in the error.
+func (l *lenientInterpolation) interpolate(name, value string) string {
+	interpolated, err := l.ip.InterpolateVariables(value, l.variables)
+	if err != nil {
+		if l.err == nil {
+			l.err = fmt.Errorf("error interpolating %s: %w", name, err)
+		}
+		return value
+	}
+	return interpolated
 }
 
 // extractSoleVariableRef checks if a string is exactly a single variable reference
@@ -146,7 +249,12 @@ func extractSoleVariableRef(s string) (string, bool) {
 		strings.Count(s, "${") == 1 &&
 		!strings.HasPrefix(s, "${env:") &&
 		!strings.Contains(s, "(") {
-		return s[2 : len(s)-1], true
+		name := s[2 : len(s)-1]
+		// Strip any default value: ${name:-default}
+		if idx := strings.Index(name, ":-"); idx >= 0 {
+			name = name[:idx]
+		}
+		return name, true
 	}
 	return "", false
 }
@@ -169,24 +277,35 @@ func CoerceVariableValue(variable Variable) (interface{}, error) {
 
 // InterpolateObject recursively interpolates variables in an object (map, slice, or scalar value)
 func InterpolateObject(obj interface{}, variables map[string]Variable) (interface{}, error) {
+	return defaultInterpolator.InterpolateObject(obj, variables)
+}
+
+// InterpolateObject recursively interpolates variables in an object (map, slice, or scalar value)
+func (ip *Interpolator) InterpolateObject(obj interface{}, variables map[string]Variable) (interface{}, error) {
+	in := &interpolation{ip: ip, variables: variables}
+	return in.object(obj, "")
+}
+
+// object recursively interpolates an object found at location in the request
+func (in *interpolation) object(obj interface{}, location string) (interface{}, error) {
 	switch v := obj.(type) {
 	case string:
 		// If the entire string is a single variable reference, apply type coercion
 		if name, ok := extractSoleVariableRef(v); ok {
-			if variable, exists := variables[name]; exists {
+			if variable, exists := in.variables[name]; exists {
 				return CoerceVariableValue(variable)
 			}
 		}
-		return InterpolateVariables(v, variables)
+		return in.value(v, location)
 	case map[string]interface{}:
 		result := make(map[string]interface{})
 		for key, val := range v {
-			interpolatedKey, err := InterpolateVariables(key, variables)
+			interpolatedKey, err := in.value(key, location)
 			if err != nil {
 				return nil, fmt.Errorf("error interpolating map key: %w", err)
 			}
 
-			interpolatedVal, err := InterpolateObject(val, variables)
+			interpolatedVal, err := in.object(val, location+"."+key)
 			if err != nil {
 				return nil, fmt.Errorf("error interpolating map value: %w", err)
 			}
@@ -197,7 +316,7 @@ func InterpolateObject(obj interface{}, variables map[string]Variable) (interfac
 	case []interface{}:
 		result := make([]interface{}, len(v))
 		for i, val := range v {
-			interpolatedVal, err := InterpolateObject(val, variables)
+			interpolatedVal, err := in.object(val, fmt.Sprintf("%s[%d]", location, i))
 			if err != nil {
 				return nil, fmt.Errorf("error interpolating slice element: %w", err)
 			}
@@ -210,51 +329,100 @@ func InterpolateObject(obj interface{}, variables map[string]Variable) (interfac
 	}
 }
 
-// InterpolateVariableValues interpolates variable templates definted in the variable values of a map[string]Variable
+// InterpolateVariableValues interpolates variable templates definted in the variable values of a map[string]Variable.
+// Variables may reference each other in any order; see ResolveVariables.
 func InterpolateVariableValues(variables map[string]Variable) error {
-	if variables == nil {
-		return nil
-	}
+	return defaultInterpolator.ResolveVariables(variables, nil)
+}
 
-	for name, variable := range variables {
-		if variable.Value == "" {
-			continue
-		}
+// InterpolateRequest applies variable interpolation to all fields in a Request
+func InterpolateRequest(request *Request, variables map[string]Variable) error {
+	return defaultInterpolator.InterpolateRequest(request, variables)
+}
 
-		// Process environment variables and cross-variable references
-		interpolated, err := InterpolateVariables(variable.Value, variables)
-		if err != nil {
-			return fmt.Errorf("error interpolating variables in variable %s: %w", name, err)
-		}
+// InterpolateRequest applies variable interpolation to all fields in a Request
+func (ip *Interpolator) InterpolateRequest(request *Request, variables map[string]Variable) error {
+	_, err := ip.interpolateRequest(request, variables)
+	return err
+}
 
-		// Update the variable value with the interpolated value
-		variable.Value = interpolated
-		variables[name] = variable
+// InterpolateRequestStrict applies variable interpolation to all fields in a Request like
+// InterpolateRequest, and returns an *UndefinedVariableError for the first reference that
+// could not be resolved. Only the request's own references are checked, so values that
+// contain ${...} are sent as they are.
+func (ip *Interpolator) InterpolateRequestStrict(request *Request, variables map[string]Variable) error {
+	unresolved, err := ip.interpolateRequest(request, variables)
+	if err != nil {
+		return err
+	}
+	if unresolved != nil {
+		return unresolved
 	}
-
 	return nil
 }
 
-// InterpolateRequest applies variable interpolation to all fields in a Request
-func InterpolateRequest(request *Request, variables map[string]Variable) error {
+// interpolateRequest interpolates the request and returns the first reference that could
+// not be resolved, or nil
+func (ip *Interpolator) interpolateRequest(request *Request, variables map[string]Variable) (*UndefinedVariableError, error) {
+	in := &interpolation{ip: ip, variables: variables}
 	var err error
 
 	// Interpolate URL
-	request.URL, err = InterpolateVariables(request.URL, variables)
+	request.URL, err = in.value(request.URL, "request.url")
 	if err != nil {
-		return fmt.Errorf("error interpolating URL: %w", err)
+		return nil, fmt.Errorf("error interpolating URL: %w", err)
 	}
 
-	// Interpolate headers
-	for i := range request.Headers {
-		request.Headers[i].Key, err = InterpolateVariables(request.Headers[i].Key, variables)
-		if err != nil {
-			return fmt.Errorf("error interpolating header key: %w", err)
+	request.Socket, err = in.value(request.Socket, "request.socket")
+	if err != nil {
+		return nil, fmt.Errorf("error interpolating socket: %w", err)
+	}
+
+	request.BaseURL, err = in.value(request.BaseURL, "defaults.base_url")
+	if err != nil {
+		return nil, fmt.Errorf("error interpolating base URL: %w", err)
+	}
+
+	// Interpolate query parameters into a new map, which may be shared with the test case
+	if len(request.Query) > 0 {
+		query := make(map[string]string, len(request.Query))
+		for k, v := range request.Query {
+			query[k], err = in.value(v, fmt.Sprintf("request.query[%s]", k))
+			if err != nil {
+				return nil, fmt.Errorf("error interpolating query parameter %s: %w", k, err)
+			}
 		}
+		request.Query = query
+	}
 
-		request.Headers[i].Value, err = InterpolateVariables(request.Headers[i].Value, variables)
-		if err != nil {
-			return fmt.Errorf("error interpolating header value: %w", err)
+	// Interpolate headers into a new slice, which may be shared with the test case
+	if len(request.Headers) > 0 {
+		headers := make([]RequestHeader, len(request.Headers))
+		for i, header := range request.Headers {
+			location := fmt.Sprintf("request.headers[%s]", header.Key)
+			header.Key, err = in.value(header.Key, location)
+			if err != nil {
+				return nil, fmt.Errorf("error interpolating header key: %w", err)
+			}
+
+			header.Value, err = in.value(header.Value, location)
+			if err != nil {
+				return nil, fmt.Errorf("error interpolating header value: %w", err)
+			}
+			headers[i] = header
+		}
+		request.Headers = headers
+	}
+
+	// Interpolate auth and signing
+	if request.Auth != nil {
+		if err := request.Auth.interpolate(in); err != nil {
+			return nil, err
+		}
+	}
+	if request.Sign != nil {
+		if err := request.Sign.interpolate(in); err != nil {
+			return nil, err
 		}
 	}
 
@@ -262,26 +430,26 @@ func InterpolateRequest(request *Request, variables map[string]Variable) error {
 	if request.Body.Type == "json" && request.Body.Data != nil {
 		// Handle string JSON body
 		if strData, ok := request.Body.Data.(string); ok {
-			interpolated, err := InterpolateVariables(strData, variables)
+			interpolated, err := in.value(strData, "request.body")
 			if err != nil {
-				return fmt.Errorf("error interpolating body string: %w", err)
+				return nil, fmt.Errorf("error interpolating body string: %w", err)
 			}
 			request.Body.Data = interpolated
 		} else {
 			// Handle structured JSON body
-			interpolated, err := InterpolateObject(request.Body.Data, variables)
+			interpolated, err := in.object(request.Body.Data, "request.body")
 			if err != nil {
-				return fmt.Errorf("error interpolating body object: %w", err)
+				return nil, fmt.Errorf("error interpolating body object: %w", err)
 			}
 			request.Body.Data = interpolated
 		}
 	}
 
-	return nil
+	return in.unresolved, nil
 }
 
 // processRandomFunction generates a random string of the specified length
-func processRandomFunction(args []string) string {
+func processRandomFunction(rng *RandomSource, args []string) string {
 	// Default length
 	length := 10
 
@@ -297,7 +465,7 @@ func processRandomFunction(args []string) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	result := make([]byte, length)
 	for i := range result {
-		result[i] = charset[random.Intn(len(charset))]
+		result[i] = charset[rng.Intn(len(charset))]
 	}
 
 	return string(result)
@@ -322,7 +490,7 @@ func processNowFunction() string {
 }
 
 // processUUIDFunction generates a random UUID
-func processUUIDFunction() string {
-	value := uuid.Must(uuid.NewRandom())
+func processUUIDFunction(rng *RandomSource) string {
+	value := uuid.Must(uuid.NewRandomFromReader(rng))
 	return value.String()
 }
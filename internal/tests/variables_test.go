@@ -1,8 +1,12 @@
 package tests
 
 import (
+	"context"
 	"os"
 	"testing"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
+	"github.com/mrfoh/httpprobe/pkg/easyreq"
 )
 
 func TestInterpolateVariables(t *testing.T) {
@@ -310,3 +314,190 @@ func TestInterpolateRequest(t *testing.T) {
 	}
 }
 
+func TestInterpolateVariables_DefaultValues(t *testing.T) {
+	os.Setenv("TEST_DEFAULT_HOST", "env.example.com")
+	defer os.Unsetenv("TEST_DEFAULT_HOST")
+
+	variables := map[string]Variable{
+		"name": {Type: "string", Value: "John"},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"${name:-Jane}", "John"},
+		{"${missing:-Jane}", "Jane"},
+		{"${missing:-}", ""},
+		{"${env:TEST_DEFAULT_HOST:-localhost}", "env.example.com"},
+		{"${env:NONEXISTENT_VAR:-localhost}", "localhost"},
+		{"http://${env:NONEXISTENT_VAR:-localhost:8080}/${missing:-v1}", "http://localhost:8080/v1"},
+	}
+
+	for _, tt := range tests {
+		got, err := InterpolateVariables(tt.input, variables)
+		if err != nil {
+			t.Errorf("InterpolateVariables(%q) returned an error: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("InterpolateVariables(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolateRequestStrict(t *testing.T) {
+	variables := map[string]Variable{
+		"echo":     {Type: "string", Value: "hello ${name}"},
+		"template": {Type: "string", Value: "${sha256(${body})} ${env:HOME} ${vault:kv/api}"},
+	}
+
+	tests := []struct {
+		name     string
+		request  Request
+		wantName string
+		wantLoc  string
+	}{
+		{
+			name:    "fully resolved",
+			request: Request{URL: "https://api.example.com/users"},
+		},
+		{
+			name:     "url",
+			request:  Request{URL: "https://${base_url}/users"},
+			wantName: "base_url",
+			wantLoc:  "request.url",
+		},
+		{
+			name: "env header",
+			request: Request{
+				URL:     "https://api.example.com",
+				Headers: []RequestHeader{{Key: "Authorization", Value: "Bearer ${env:API_TOKEN}"}},
+			},
+			wantName: "env:API_TOKEN",
+			wantLoc:  "request.headers[Authorization]",
+		},
+		{
+			name: "nested body",
+			request: Request{
+				URL: "https://api.example.com",
+				Body: RequestBody{
+					Type: "json",
+					Data: map[string]interface{}{
+						"items": []interface{}{map[string]interface{}{"id": "${item_id}"}},
+					},
+				},
+			},
+			wantName: "item_id",
+			wantLoc:  "request.body.items[0].id",
+		},
+		{
+			name: "unknown provider",
+			request: Request{
+				URL:     "https://api.example.com",
+				Headers: []RequestHeader{{Key: "X-Api-Key", Value: "${vault:kv/api}"}},
+			},
+			wantName: "vault:kv/api",
+			wantLoc:  "request.headers[X-Api-Key]",
+		},
+		{
+			name:     "unknown function",
+			request:  Request{URL: "https://api.example.com/${unknownFunc(1)}"},
+			wantName: "unknownFunc()",
+			wantLoc:  "request.url",
+		},
+		{
+			name: "malformed function call",
+			request: Request{
+				URL:     "https://api.example.com",
+				Headers: []RequestHeader{{Key: "X-Signature", Value: "${sha256(abc}"}},
+			},
+			wantName: "sha256()",
+			wantLoc:  "request.headers[X-Signature]",
+		},
+		{
+			name:    "values containing references",
+			request: Request{URL: "https://api.example.com/${echo}", Headers: []RequestHeader{{Key: "X-Template", Value: "${template}"}}},
+		},
+		{
+			name:     "reference with spaces",
+			request:  Request{URL: "https://api.example.com/${ tok }"},
+			wantName: "${ tok }",
+			wantLoc:  "request.url",
+		},
+		{
+			name: "reference with an invalid provider name",
+			request: Request{
+				URL:  "https://api.example.com",
+				Body: RequestBody{Type: "json", Data: map[string]interface{}{"key": "${Foo:bar}"}},
+			},
+			wantName: "${Foo:bar}",
+			wantLoc:  "request.body.key",
+		},
+		{
+			name: "unresolved auth field",
+			request: Request{
+				URL:  "https://api.example.com",
+				Auth: &Auth{Type: "bearer", Token: "${token}"},
+			},
+			wantName: "token",
+			wantLoc:  "request.auth.token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Interpolator{}).InterpolateRequestStrict(&tt.request, variables)
+			if tt.wantName == "" {
+				if err != nil {
+					t.Errorf("InterpolateRequestStrict() returned an error: %v", err)
+				}
+				return
+			}
+
+			undefinedErr, ok := err.(*UndefinedVariableError)
+			if !ok {
+				t.Fatalf("InterpolateRequestStrict() error = %v, want *UndefinedVariableError", err)
+			}
+			if undefinedErr.Name != tt.wantName || undefinedErr.Location != tt.wantLoc {
+				t.Errorf("InterpolateRequestStrict() = %q in %q, want %q in %q",
+					undefinedErr.Name, undefinedErr.Location, tt.wantName, tt.wantLoc)
+			}
+		})
+	}
+}
+
+func TestExecCase_StrictVariables(t *testing.T) {
+	logger := logging.NewMockLogger()
+	client := easyreq.NewHttpClientMock()
+
+	testCase := &TestCase{
+		Title:   "undefined base url",
+		Request: Request{Method: "GET", URL: "${base_url}/users"},
+	}
+
+	suite := &TestSuite{Name: "strict", Cases: []TestCase{*testCase}}
+	result, err := suite.Run(context.Background(), logger, client)
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	caseResult := result.Cases[testCase.Title]
+	if caseResult.Passed || !caseResult.Errored {
+		t.Errorf("expected errored case, got %+v", caseResult)
+	}
+	if len(client.GetCalls) != 0 {
+		t.Errorf("expected no request to be sent, got %v", client.GetCalls)
+	}
+
+	// Disabling strict mode sends the request as-is
+	suite.Config = map[string]interface{}{"strict": false}
+	result, err = suite.Run(context.Background(), logger, client)
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	if caseResult := result.Cases[testCase.Title]; caseResult.Errored {
+		t.Errorf("expected case to run with strict mode disabled, got %+v", caseResult)
+	}
+	if len(client.GetCalls) != 1 || client.GetCalls[0] != "${base_url}/users" {
+		t.Errorf("expected request to ${base_url}/users, got %v", client.GetCalls)
+	}
+}
@@ -2,19 +2,28 @@ package easyreq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
-	"slices"
-	
+	"sync"
+	"time"
+
+	"github.com/mrfoh/httpprobe/internal/logging"
 	"go.uber.org/zap"
 )
 
 type HttpClientImpl struct {
 	Client *http.Client
 	Opts   *HttpClientOptions
+	// configErr is an error in the options, returned by every request
+	configErr error
+	// sockets are the transports for unix socket requests, by socket path
+	sockets   map[string]*http.Transport
+	socketsMu sync.Mutex
 }
 
 type HttpRequest struct {
@@ -23,192 +32,384 @@ type HttpRequest struct {
 	Headers map[string]interface{}
 	Query   map[string]interface{}
 	Body    interface{}
+	// Digest enables HTTP Digest authentication with the given credentials
+	Digest *DigestAuth
+	// Signer signs the request after it is built
+	Signer RequestSigner
+	// Timeout overrides the client's timeout for the request
+	Timeout time.Duration
+	// Jar overrides the client's cookie jar for the request
+	Jar http.CookieJar
+	// MaxRedirects is the maximum number of redirects to follow
+	MaxRedirects *int
+	// Socket is the path of a unix socket to send the request over
+	Socket string
+	// RemoveHeaders are headers that are not sent, even when the client sets them
+	RemoveHeaders []string
+	// BaseUrl overrides the client's base URL for the request
+	BaseUrl string
+}
+
+// NewRequest returns the request for a method, URL and body with the params. The method
+// can be any method, e.g. PROPFIND or PURGE, and body can be nil.
+func NewRequest(method string, requestUrl string, body interface{}, params RequestParams) HttpRequest {
+	return HttpRequest{
+		Method:        method,
+		Url:           requestUrl,
+		Body:          body,
+		Headers:       params.Headers,
+		Query:         params.Query,
+		Digest:        params.Digest,
+		Signer:        params.Signer,
+		Timeout:       params.Timeout,
+		Jar:           params.Jar,
+		MaxRedirects:  params.MaxRedirects,
+		Socket:        params.Socket,
+		RemoveHeaders: params.RemoveHeaders,
+		BaseUrl:       params.BaseUrl,
+	}
+}
+
+// params returns the RequestParams of the request
+func (r HttpRequest) params() RequestParams {
+	return RequestParams{
+		Headers:       r.Headers,
+		Query:         r.Query,
+		Digest:        r.Digest,
+		Signer:        r.Signer,
+		Timeout:       r.Timeout,
+		Jar:           r.Jar,
+		MaxRedirects:  r.MaxRedirects,
+		Socket:        r.Socket,
+		RemoveHeaders: r.RemoveHeaders,
+		BaseUrl:       r.BaseUrl,
+	}
 }
 
 func New(opts *HttpClientOptions) HttpClient {
-	return &HttpClientImpl{
+	client := &HttpClientImpl{
 		Opts: opts,
+		// Timeouts are applied per request through the request context
 		Client: &http.Client{
-			Timeout: opts.GetTimeout(),
+			Jar: opts.Jar,
 		},
 	}
+
+	if opts.TLS != nil || opts.Proxy != nil || len(opts.Resolve) > 0 || opts.HTTPVersion != "" {
+		transport, err := newTransport(opts)
+		if err != nil {
+			// Invalid options fail every request instead of silently connecting without them
+			client.configErr = err
+		} else {
+			client.Client.Transport = transport
+		}
+	}
+
+	return client
 }
 
-// makeRequest is a private method that makes the actual request to the server
-func (c *HttpClientImpl) makeRequest(req HttpRequest) (*HttpResponse, error) {
-	var request *http.Request
-	var result *HttpResponse
-	var err error
+// newTransport returns a copy of the default transport with the TLS, proxy, resolve and
+// HTTP version options
+func newTransport(opts *HttpClientOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
 
-	requestUrl := c.requestUrl(req.Url, req.Query)
+	if opts.TLS != nil {
+		tlsConfig, err := opts.TLS.Config()
+		if err != nil {
+			return nil, fmt.Errorf("error configuring TLS: %v", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
 
-	if slices.Contains([]string{"POST", "PUT", "PATCH"}, req.Method) && req.Body != nil {
-		var body []byte
-		body, err = json.Marshal(req.Body)
+	if opts.Proxy != nil {
+		proxy, err := opts.Proxy.proxyFunc()
 		if err != nil {
-			return nil, fmt.Errorf("error marshaling request body: %v", err)
+			return nil, fmt.Errorf("error configuring proxy: %v", err)
 		}
-		request, err = http.NewRequest(req.Method, requestUrl, bytes.NewBuffer(body))
-		if err == nil {
-			request.Header.Set("Content-Type", "application/json")
+		transport.Proxy = proxy
+	}
+
+	if len(opts.Resolve) > 0 {
+		if err := validateResolve(opts.Resolve); err != nil {
+			return nil, err
 		}
-	} else {
-		request, err = http.NewRequest(req.Method, requestUrl, nil)
+		transport.DialContext = resolveDialer(opts.Resolve)
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+	if err := configureHTTPVersion(transport, opts.HTTPVersion); err != nil {
+		return nil, fmt.Errorf("error configuring HTTP version: %v", err)
 	}
 
-	// Add client-level headers
-	if c.Opts.Headers != nil {
-		for k, v := range c.Opts.Headers {
-			request.Header.Add(k, fmt.Sprintf("%v", v))
-		}
+	return transport, nil
+}
+
+// Do sends the request to the server. Any method can be used, and the body is sent for
+// every method that has one.
+func (c *HttpClientImpl) Do(ctx context.Context, req HttpRequest) (*HttpResponse, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
 	}
 
-	// Add request-specific headers (overriding client-level headers)
-	if req.Headers != nil {
-		for k, v := range req.Headers {
-			request.Header.Set(k, fmt.Sprintf("%v", v))
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = c.Opts.GetTimeout()
+	}
+	requestCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Track the phase of the request so timeouts can report where the time was spent
+	phase := newPhaseTracker()
+	requestCtx = httptrace.WithClientTrace(requestCtx, phase.clientTrace())
+
+	var body []byte
+	var err error
+
+	// Unix socket targets are sent over the socket without the base URL
+	socket := req.Socket
+	var requestUrl string
+	if unixSocket, path, ok := parseUnixTarget(req.Url); ok {
+		socket = unixSocket
+		requestUrl = addQuery(path, req.Query, c.Opts.Logger)
+	} else {
+		requestUrl = c.requestUrl(req.Url, req.BaseUrl, req.Query)
+	}
+	if socket != "" {
+		requestUrl = socketUrl(requestUrl)
+	}
+
+	contentType := "application/json"
+	if req.Body != nil {
+		// url.Values bodies are sent as a form, everything else as JSON
+		if form, ok := req.Body.(url.Values); ok {
+			body = []byte(form.Encode())
+			contentType = "application/x-www-form-urlencoded"
+		} else {
+			body, err = json.Marshal(req.Body)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling request body: %v", err)
+			}
 		}
 	}
 
+	request, err := c.newRequest(requestCtx, req, requestUrl, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.sign(req, request, body); err != nil {
+		return nil, err
+	}
+
+	// Each request uses a copy of the client that shares its transport, with the request's
+	// redirect policy and cookie jar
+	var redirects []Redirect
+	client := *c.Client
+	client.CheckRedirect = redirectPolicy(req.MaxRedirects, &redirects)
+	if req.Jar != nil {
+		client.Jar = req.Jar
+	}
+	if socket != "" {
+		client.Transport = c.socketTransport(socket)
+	}
+
 	// Execute the request
-	resp, err := c.Client.Do(request)
+	resp, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, phase.requestError(ctx, requestCtx, timeout, err)
+	}
+
+	// Answer a digest challenge and send the request again
+	if resp.StatusCode == http.StatusUnauthorized && req.Digest != nil {
+		if challenge, ok := findDigestChallenge(resp.Header); ok {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			authorization, err := challenge.authorization(req.Digest, req.Method, request.URL.RequestURI(), body)
+			if err != nil {
+				return nil, fmt.Errorf("error answering digest challenge: %v", err)
+			}
+
+			request, err = c.newRequest(requestCtx, req, requestUrl, body, contentType)
+			if err != nil {
+				return nil, err
+			}
+			request.Header.Set("Authorization", authorization)
+			if err := c.sign(req, request, body); err != nil {
+				return nil, err
+			}
+
+			redirects = nil
+			resp, err = client.Do(request)
+			if err != nil {
+				return nil, phase.requestError(ctx, requestCtx, timeout, err)
+			}
+		}
 	}
 	defer resp.Body.Close()
 
 	// Read response body
+	phase.set(PhaseReadingBody)
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if requestCtx.Err() != nil {
+			return nil, phase.requestError(ctx, requestCtx, timeout, err)
+		}
 		return nil, fmt.Errorf("error reading response body: %v", err)
 	}
 
-	result = &HttpResponse{
-		Status:  resp.StatusCode,
-		Headers: resp.Header,
-		Body:    bodyBytes,
+	result := &HttpResponse{
+		Status:    resp.StatusCode,
+		Headers:   resp.Header,
+		Body:      bodyBytes,
+		TLS:       newTLSInfo(resp.TLS),
+		URL:       resp.Request.URL.String(),
+		Redirects: redirects,
+		Protocol:  resp.Proto,
 	}
 
 	return result, nil
 }
 
-// requestUrl constructs the full request URL
-func (c *HttpClientImpl) requestUrl(value string, query map[string]interface{}) string {
-	var baseUrl string
+// sign signs request with the request's signer, or the client's signer
+func (c *HttpClientImpl) sign(req HttpRequest, request *http.Request, body []byte) error {
+	signer := req.Signer
+	if signer == nil {
+		signer = c.Opts.Signer
+	}
+	if signer == nil {
+		return nil
+	}
 
-	// Determine base URL
-	if c.Opts.BaseUrl != "" {
-		joined, err := url.JoinPath(c.Opts.BaseUrl, value)
-		if err != nil {
-			baseUrl = fmt.Sprintf("%s/%s", c.Opts.BaseUrl, value)
-		} else {
-			baseUrl = joined
-		}
-	} else {
-		baseUrl = value
+	if err := signer.Sign(request, body); err != nil {
+		return fmt.Errorf("error signing request: %v", err)
 	}
+	return nil
+}
 
-	// Add query parameters if present
-	if len(query) > 0 {
-		parsedUrl, err := url.Parse(baseUrl)
-		if err != nil {
-			if c.Opts.Logger != nil {
-				c.Opts.Logger.Warn(fmt.Sprintf("Error parsing URL: %s", baseUrl), zap.Error(err))
-			}
-			return baseUrl
-		}
+// newRequest creates the http.Request for req with the client and request headers
+func (c *HttpClientImpl) newRequest(ctx context.Context, req HttpRequest, requestUrl string, body []byte, contentType string) (*http.Request, error) {
+	var request *http.Request
+	var err error
 
-		q := parsedUrl.Query()
-		for k, v := range query {
-			q.Add(k, fmt.Sprintf("%v", v))
+	if body != nil {
+		request, err = http.NewRequestWithContext(ctx, req.Method, requestUrl, bytes.NewBuffer(body))
+		if err == nil {
+			request.Header.Set("Content-Type", contentType)
 		}
-		parsedUrl.RawQuery = q.Encode()
-		return parsedUrl.String()
+	} else {
+		request, err = http.NewRequestWithContext(ctx, req.Method, requestUrl, nil)
 	}
 
-	return baseUrl
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// An empty User-Agent stops net/http from sending its own default
+	request.Header.Set("User-Agent", c.Opts.UserAgent)
+
+	// Add client-level headers, then request-specific headers. Each replaces the values the
+	// request already has for the header, so request headers override client headers.
+	setHeaders(request.Header, c.Opts.Headers)
+	setHeaders(request.Header, req.Headers)
+
+	// Remove default headers the request should not send
+	removeHeaders(request.Header, req.RemoveHeaders)
+
+	return request, nil
 }
 
-func (c *HttpClientImpl) Get(requestUrl string, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodGet,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
+// requestUrl constructs the full request URL. Relative URLs are joined with the base URL,
+// base if set or the client's, and absolute URLs are used as they are.
+func (c *HttpClientImpl) requestUrl(value string, base string, query map[string]interface{}) string {
+	var baseUrl string
+
+	if base == "" {
+		base = c.Opts.BaseUrl
 	}
 
-	return c.makeRequest(req)
+	// Determine base URL
+	if base != "" && !isAbsoluteUrl(value) {
+		baseUrl = joinUrl(base, value)
+	} else {
+		baseUrl = value
+	}
+
+	return addQuery(baseUrl, query, c.Opts.Logger)
+}
+
+// isAbsoluteUrl reports whether value is a URL with a scheme and host
+func isAbsoluteUrl(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.IsAbs() && parsed.Host != ""
 }
 
-func (c *HttpClientImpl) Post(requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodPost,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
-		Body:    body,
+// joinUrl joins the path of value with the base URL, keeping the query of value
+func joinUrl(base string, value string) string {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return fmt.Sprintf("%s/%s", base, value)
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return fmt.Sprintf("%s/%s", base, value)
 	}
 
-	return c.makeRequest(req)
+	joined := baseUrl.JoinPath(ref.EscapedPath())
+	if ref.RawQuery != "" {
+		joined.RawQuery = ref.RawQuery
+	}
+	return joined.String()
 }
 
-func (c *HttpClientImpl) Put(requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodPut,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
-		Body:    body,
+// addQuery adds the query parameters to requestUrl
+func addQuery(requestUrl string, query map[string]interface{}, logger logging.Logger) string {
+	if len(query) == 0 {
+		return requestUrl
 	}
 
-	return c.makeRequest(req)
-}
+	parsedUrl, err := url.Parse(requestUrl)
+	if err != nil {
+		if logger != nil {
+			logger.Warn(fmt.Sprintf("Error parsing URL: %s", requestUrl), zap.Error(err))
+		}
+		return requestUrl
+	}
 
-func (c *HttpClientImpl) Delete(requestUrl string, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodDelete,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
+	q := parsedUrl.Query()
+	for k, v := range query {
+		q.Add(k, fmt.Sprintf("%v", v))
 	}
+	parsedUrl.RawQuery = q.Encode()
+	return parsedUrl.String()
+}
 
-	return c.makeRequest(req)
+func (c *HttpClientImpl) Get(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodGet, requestUrl, nil, params))
 }
 
-func (c *HttpClientImpl) Options(requestUrl string, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodOptions,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
-	}
+func (c *HttpClientImpl) Post(ctx context.Context, requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodPost, requestUrl, body, params))
+}
 
-	return c.makeRequest(req)
+func (c *HttpClientImpl) Put(ctx context.Context, requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodPut, requestUrl, body, params))
 }
 
-func (c *HttpClientImpl) Head(requestUrl string, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodHead,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
-	}
+func (c *HttpClientImpl) Delete(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodDelete, requestUrl, nil, params))
+}
 
-	return c.makeRequest(req)
+func (c *HttpClientImpl) Options(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodOptions, requestUrl, nil, params))
 }
 
-func (c *HttpClientImpl) Patch(requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error) {
-	req := HttpRequest{
-		Method:  http.MethodPatch,
-		Url:     requestUrl,
-		Headers: params.Headers,
-		Query:   params.Query,
-		Body:    body,
-	}
+func (c *HttpClientImpl) Head(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodHead, requestUrl, nil, params))
+}
 
-	return c.makeRequest(req)
-}
\ No newline at end of file
+func (c *HttpClientImpl) Patch(ctx context.Context, requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error) {
+	return c.Do(ctx, NewRequest(http.MethodPatch, requestUrl, body, params))
+}
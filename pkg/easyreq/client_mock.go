@@ -1,6 +1,7 @@
 package easyreq
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
 	"net/http"
 )
@@ -17,19 +18,22 @@ type HttpClientMock struct {
 	PatchCalls   []string
 	HeadCalls    []string
 	OptionsCalls []string
+	// DoCalls are the methods and URLs of requests sent with Do, e.g. "PURGE /cache"
+	DoCalls []string
 	
 	// Default response to return if mock.Called is not used
 	MockResponse *HttpResponse
 	MockError    error
 	
 	// Custom implementations for HTTP methods (override mock.Called behavior)
-	CustomGet     func(url string, params RequestParams) (*HttpResponse, error)
-	CustomPost    func(url string, body interface{}, params RequestParams) (*HttpResponse, error)
-	CustomPut     func(url string, body interface{}, params RequestParams) (*HttpResponse, error)
-	CustomDelete  func(url string, params RequestParams) (*HttpResponse, error)
-	CustomPatch   func(url string, body interface{}, params RequestParams) (*HttpResponse, error)
-	CustomHead    func(url string, params RequestParams) (*HttpResponse, error)
-	CustomOptions func(url string, params RequestParams) (*HttpResponse, error)
+	CustomGet     func(ctx context.Context, url string, params RequestParams) (*HttpResponse, error)
+	CustomPost    func(ctx context.Context, url string, body interface{}, params RequestParams) (*HttpResponse, error)
+	CustomPut     func(ctx context.Context, url string, body interface{}, params RequestParams) (*HttpResponse, error)
+	CustomDelete  func(ctx context.Context, url string, params RequestParams) (*HttpResponse, error)
+	CustomPatch   func(ctx context.Context, url string, body interface{}, params RequestParams) (*HttpResponse, error)
+	CustomHead    func(ctx context.Context, url string, params RequestParams) (*HttpResponse, error)
+	CustomOptions func(ctx context.Context, url string, params RequestParams) (*HttpResponse, error)
+	CustomDo      func(ctx context.Context, req HttpRequest) (*HttpResponse, error)
 }
 
 // NewHttpClientMock creates a new instance of HttpClientMock
@@ -49,18 +53,58 @@ func NewHttpClientMock() *HttpClientMock {
 		PatchCalls:   make([]string, 0),
 		HeadCalls:    make([]string, 0),
 		OptionsCalls: make([]string, 0),
+		DoCalls:      make([]string, 0),
 		MockResponse: defaultResponse,
 	}
 }
 
+// Do uses CustomDo when it is set, and otherwise the mock of the request's method, so
+// tests that mock Get or Post also work for requests sent with Do
+func (m *HttpClientMock) Do(ctx context.Context, req HttpRequest) (*HttpResponse, error) {
+	// Track the call
+	m.DoCalls = append(m.DoCalls, req.Method+" "+req.Url)
+	
+	// Use custom implementation if provided
+	if m.CustomDo != nil {
+		return m.CustomDo(ctx, req)
+	}
+	
+	params := req.params()
+	switch req.Method {
+	case http.MethodGet:
+		return m.Get(ctx, req.Url, params)
+	case http.MethodPost:
+		return m.Post(ctx, req.Url, req.Body, params)
+	case http.MethodPut:
+		return m.Put(ctx, req.Url, req.Body, params)
+	case http.MethodDelete:
+		return m.Delete(ctx, req.Url, params)
+	case http.MethodOptions:
+		return m.Options(ctx, req.Url, params)
+	case http.MethodHead:
+		return m.Head(ctx, req.Url, params)
+	case http.MethodPatch:
+		return m.Patch(ctx, req.Url, req.Body, params)
+	}
+	
+	// Use testify/mock if args are defined
+	if len(m.Mock.ExpectedCalls) > 0 {
+		args := m.Called(req)
+		return args.Get(0).(*HttpResponse), args.Error(1)
+	}
+	
+	// Default behavior
+	return m.MockResponse, m.MockError
+}
+
 // Custom HTTP methods with flexible implementation patterns
-func (m *HttpClientMock) Get(url string, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Get(ctx context.Context, url string, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.GetCalls = append(m.GetCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomGet != nil {
-		return m.CustomGet(url, params)
+		return m.CustomGet(ctx, url, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -73,13 +117,13 @@ func (m *HttpClientMock) Get(url string, params RequestParams) (*HttpResponse, e
 	return m.MockResponse, m.MockError
 }
 
-func (m *HttpClientMock) Post(url string, body interface{}, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Post(ctx context.Context, url string, body interface{}, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.PostCalls = append(m.PostCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomPost != nil {
-		return m.CustomPost(url, body, params)
+		return m.CustomPost(ctx, url, body, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -92,13 +136,13 @@ func (m *HttpClientMock) Post(url string, body interface{}, params RequestParams
 	return m.MockResponse, m.MockError
 }
 
-func (m *HttpClientMock) Put(url string, body interface{}, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Put(ctx context.Context, url string, body interface{}, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.PutCalls = append(m.PutCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomPut != nil {
-		return m.CustomPut(url, body, params)
+		return m.CustomPut(ctx, url, body, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -111,13 +155,13 @@ func (m *HttpClientMock) Put(url string, body interface{}, params RequestParams)
 	return m.MockResponse, m.MockError
 }
 
-func (m *HttpClientMock) Delete(url string, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Delete(ctx context.Context, url string, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.DeleteCalls = append(m.DeleteCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomDelete != nil {
-		return m.CustomDelete(url, params)
+		return m.CustomDelete(ctx, url, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -130,13 +174,13 @@ func (m *HttpClientMock) Delete(url string, params RequestParams) (*HttpResponse
 	return m.MockResponse, m.MockError
 }
 
-func (m *HttpClientMock) Options(url string, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Options(ctx context.Context, url string, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.OptionsCalls = append(m.OptionsCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomOptions != nil {
-		return m.CustomOptions(url, params)
+		return m.CustomOptions(ctx, url, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -149,13 +193,13 @@ func (m *HttpClientMock) Options(url string, params RequestParams) (*HttpRespons
 	return m.MockResponse, m.MockError
 }
 
-func (m *HttpClientMock) Head(url string, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Head(ctx context.Context, url string, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.HeadCalls = append(m.HeadCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomHead != nil {
-		return m.CustomHead(url, params)
+		return m.CustomHead(ctx, url, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -168,13 +212,13 @@ func (m *HttpClientMock) Head(url string, params RequestParams) (*HttpResponse,
 	return m.MockResponse, m.MockError
 }
 
-func (m *HttpClientMock) Patch(url string, body interface{}, params RequestParams) (*HttpResponse, error) {
+func (m *HttpClientMock) Patch(ctx context.Context, url string, body interface{}, params RequestParams) (*HttpResponse, error) {
 	// Track the call
 	m.PatchCalls = append(m.PatchCalls, url)
 	
 	// Use custom implementation if provided
 	if m.CustomPatch != nil {
-		return m.CustomPatch(url, body, params)
+		return m.CustomPatch(ctx, url, body, params)
 	}
 	
 	// Use testify/mock if args are defined
@@ -0,0 +1,81 @@
+package easyreq
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		body        interface{}
+		expected    string
+		contentType string
+	}{
+		{name: "custom method", method: "PURGE", expected: ""},
+		{name: "webdav method with body", method: "PROPFIND", body: map[string]interface{}{"depth": 1}, expected: `{"depth":1}`, contentType: "application/json"},
+		{name: "query with body", method: "QUERY", body: map[string]interface{}{"q": "widgets"}, expected: `{"q":"widgets"}`, contentType: "application/json"},
+		{name: "delete with body", method: http.MethodDelete, body: []string{"a", "b"}, expected: `["a","b"]`, contentType: "application/json"},
+		{name: "get without body", method: http.MethodGet, expected: ""},
+	}
+
+	client := New(NewOptions())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Do(context.Background(), NewRequest(tt.method, server.URL, tt.body, RequestParams{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			headers := http.Header(resp.Headers)
+			if headers.Get("X-Method") != tt.method {
+				t.Errorf("expected method %s, got %s", tt.method, headers.Get("X-Method"))
+			}
+			if string(resp.Body) != tt.expected || headers.Get("X-Content-Type") != tt.contentType {
+				t.Errorf("expected body %q with content type %q, got %q with %q", tt.expected, tt.contentType, resp.Body, headers.Get("X-Content-Type"))
+			}
+		})
+	}
+
+	if _, err := client.Do(context.Background(), NewRequest("BAD METHOD", server.URL, nil, RequestParams{})); err == nil {
+		t.Errorf("expected an error for an invalid method")
+	}
+}
+
+func TestRequestUrl(t *testing.T) {
+	tests := []struct {
+		baseUrl  string
+		base     string
+		url      string
+		query    map[string]interface{}
+		expected string
+	}{
+		{baseUrl: "", url: "https://api.example.com/users", expected: "https://api.example.com/users"},
+		{baseUrl: "https://api.example.com/v1", url: "/users", expected: "https://api.example.com/v1/users"},
+		{baseUrl: "https://api.example.com/v1/", url: "users/42", expected: "https://api.example.com/v1/users/42"},
+		{baseUrl: "https://api.example.com/v1", url: "/users?page=2", expected: "https://api.example.com/v1/users?page=2"},
+		{baseUrl: "https://api.example.com/v1", url: "/users", query: map[string]interface{}{"page": 2}, expected: "https://api.example.com/v1/users?page=2"},
+		{baseUrl: "https://api.example.com/v1", url: "https://auth.example.com/token", expected: "https://auth.example.com/token"},
+		// A request base URL replaces the client's
+		{baseUrl: "https://api.example.com/v1", base: "https://admin.example.com", url: "/users", expected: "https://admin.example.com/users"},
+		{base: "https://admin.example.com", url: "https://auth.example.com/token", expected: "https://auth.example.com/token"},
+	}
+
+	for _, tt := range tests {
+		client := New(NewOptions().WithBaseUrl(tt.baseUrl)).(*HttpClientImpl)
+		if actual := client.requestUrl(tt.url, tt.base, tt.query); actual != tt.expected {
+			t.Errorf("requestUrl(%q) with base %q = %q, expected %q", tt.url, tt.baseUrl, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package easyreq
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// DigestAuth holds the credentials for HTTP Digest authentication (RFC 7616). The client
+// answers the server's challenge and retries the request.
+type DigestAuth struct {
+	Username string
+	Password string
+}
+
+// digestChallenge is a parsed WWW-Authenticate: Digest challenge
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+// findDigestChallenge returns the Digest challenge from the WWW-Authenticate headers of a response
+func findDigestChallenge(headers http.Header) (*digestChallenge, bool) {
+	for _, value := range headers.Values("WWW-Authenticate") {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+
+		fields := parseAuthParams(params)
+		challenge := &digestChallenge{
+			realm:     fields["realm"],
+			nonce:     fields["nonce"],
+			opaque:    fields["opaque"],
+			algorithm: fields["algorithm"],
+		}
+
+		// Prefer qop=auth, which does not need the body
+		for _, qop := range strings.Split(fields["qop"], ",") {
+			qop = strings.TrimSpace(qop)
+			if qop == "auth" || (qop == "auth-int" && challenge.qop == "") {
+				challenge.qop = qop
+			}
+		}
+
+		return challenge, true
+	}
+
+	return nil, false
+}
+
+// parseAuthParams parses comma separated key=value auth parameters, where values may be quoted
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		key, rest, found := strings.Cut(s, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var sb strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				sb.WriteByte(rest[i])
+			}
+			value = sb.String()
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value = strings.TrimSpace(rest[:end])
+			s = rest[end:]
+		}
+
+		params[key] = value
+	}
+
+	return params
+}
+
+// authorization computes the Authorization header answering the challenge for a request
+func (c *digestChallenge) authorization(auth *DigestAuth, method string, uri string, body []byte) (string, error) {
+	var newHash func() hash.Hash
+	algorithm := strings.ToUpper(c.algorithm)
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm: %s", c.algorithm)
+	}
+
+	h := func(s string) string {
+		hasher := newHash()
+		hasher.Write([]byte(s))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	cnonceBytes := make([]byte, 16)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", fmt.Errorf("error generating cnonce: %v", err)
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := "00000001"
+
+	ha1 := h(fmt.Sprintf("%s:%s:%s", auth.Username, c.realm, auth.Password))
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, cnonce))
+	}
+
+	ha2 := h(fmt.Sprintf("%s:%s", method, uri))
+	if c.qop == "auth-int" {
+		ha2 = h(fmt.Sprintf("%s:%s:%s", method, uri, h(string(body))))
+	}
+
+	var response string
+	if c.qop != "" {
+		response = h(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, c.nonce, nc, cnonce, c.qop, ha2))
+	} else {
+		response = h(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, ha2))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		auth.Username, c.realm, c.nonce, uri, response)
+	if c.algorithm != "" {
+		fmt.Fprintf(&sb, ", algorithm=%s", c.algorithm)
+	}
+	if c.qop != "" {
+		fmt.Fprintf(&sb, `, qop=%s, nc=%s, cnonce="%s"`, c.qop, nc, cnonce)
+	}
+	if c.opaque != "" {
+		fmt.Fprintf(&sb, `, opaque="%s"`, c.opaque)
+	}
+
+	return sb.String(), nil
+}
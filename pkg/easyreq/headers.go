@@ -0,0 +1,48 @@
+package easyreq
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DefaultUserAgent is the User-Agent of requests when the options do not set one
+const DefaultUserAgent = "httpprobe"
+
+// headerValues returns the values of a header. A []string or []interface{} value is sent
+// as repeated headers, in order; any other value is sent as a single header.
+func headerValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprintf("%v", item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+// setHeaders sets the headers on header, replacing any values it already has for them
+func setHeaders(header http.Header, headers map[string]interface{}) {
+	for k, v := range headers {
+		header.Del(k)
+		for _, value := range headerValues(v) {
+			header.Add(k, value)
+		}
+	}
+}
+
+// removeHeaders removes the headers from header. The User-Agent is kept with an empty
+// value, which stops net/http from sending its own default User-Agent.
+func removeHeaders(header http.Header, names []string) {
+	for _, name := range names {
+		if http.CanonicalHeaderKey(name) == "User-Agent" {
+			header.Set("User-Agent", "")
+			continue
+		}
+		header.Del(name)
+	}
+}
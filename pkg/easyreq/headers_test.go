@@ -0,0 +1,96 @@
+package easyreq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	// The server echoes the headers it received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(r.Header)
+	}))
+	defer server.Close()
+
+	client := New(NewOptions().WithHeaders(map[string]interface{}{
+		"Accept":    "application/json",
+		"X-Tenant":  "acme",
+		"X-Feature": []string{"a", "b"},
+	}))
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		params   RequestParams
+		expected map[string][]string
+	}{
+		{
+			name: "defaults",
+			expected: map[string][]string{
+				"Accept":     {"application/json"},
+				"User-Agent": {DefaultUserAgent},
+				"X-Feature":  {"a", "b"},
+			},
+		},
+		{
+			name:   "repeated request headers replace client headers",
+			params: RequestParams{Headers: map[string]interface{}{"accept": []string{"text/html", "application/xml"}, "X-Tenant": "globex"}},
+			expected: map[string][]string{
+				"Accept":   {"text/html", "application/xml"},
+				"X-Tenant": {"globex"},
+			},
+		},
+		{
+			name:   "removed headers",
+			body:   map[string]interface{}{"name": "widget"},
+			params: RequestParams{RemoveHeaders: []string{"Content-Type", "user-agent", "X-Tenant"}},
+			expected: map[string][]string{
+				"Content-Type": nil,
+				"User-Agent":   nil,
+				"X-Tenant":     nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Do(context.Background(), NewRequest(http.MethodPost, server.URL, tt.body, tt.params))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var received map[string][]string
+			if err := json.Unmarshal(resp.Body, &received); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			for name, values := range tt.expected {
+				if !reflect.DeepEqual(received[name], values) {
+					t.Errorf("expected %s to be %v, got %v", name, values, received[name])
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(r.Header)
+	}))
+	defer server.Close()
+
+	client := New(NewOptions().WithUserAgent(""))
+	resp, err := client.Do(context.Background(), NewRequest(http.MethodGet, server.URL, nil, RequestParams{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var received map[string][]string
+	if err := json.Unmarshal(resp.Body, &received); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if values, ok := received["User-Agent"]; ok {
+		t.Errorf("expected no User-Agent, got %v", values)
+	}
+}
@@ -1,22 +1,60 @@
 package easyreq
 
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// HttpClient sends HTTP requests. Requests are cancelled when ctx is done.
 type HttpClient interface {
-	Get(requestUrl string, params RequestParams) (*HttpResponse, error)
-	Post(requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error)
-	Put(requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error)
-	Delete(requestUrl string, params RequestParams) (*HttpResponse, error)
-	Options(requestUrl string, params RequestParams) (*HttpResponse, error)
-	Head(requestUrl string, params RequestParams) (*HttpResponse, error)
-	Patch(requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error)
+	// Do sends a request with any method. The other methods are shortcuts for it.
+	Do(ctx context.Context, req HttpRequest) (*HttpResponse, error)
+	Get(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error)
+	Post(ctx context.Context, requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error)
+	Put(ctx context.Context, requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error)
+	Delete(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error)
+	Options(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error)
+	Head(ctx context.Context, requestUrl string, params RequestParams) (*HttpResponse, error)
+	Patch(ctx context.Context, requestUrl string, body interface{}, params RequestParams) (*HttpResponse, error)
 }
 
 type RequestParams struct {
+	// Headers are sent with the request, replacing client headers with the same name. A
+	// []string value is sent as repeated headers, in order.
 	Headers map[string]interface{}
 	Query   map[string]interface{}
+	// Digest enables HTTP Digest authentication with the given credentials
+	Digest *DigestAuth
+	// Signer signs the request after it is built. Defaults to the client's signer.
+	Signer RequestSigner
+	// Timeout overrides the client's timeout for the request
+	Timeout time.Duration
+	// Jar stores and sends cookies for the request. Defaults to the client's jar.
+	Jar http.CookieJar
+	// MaxRedirects is the maximum number of redirects to follow, 0 to return redirect
+	// responses as they are. Defaults to DefaultMaxRedirects.
+	MaxRedirects *int
+	// Socket is the path of a unix socket to send the request over instead of connecting to
+	// the URL's host. URLs can also target a socket as unix:///path/to.sock:/http/path.
+	Socket string
+	// RemoveHeaders are headers that are not sent, such as the Content-Type, the default
+	// User-Agent or a client header
+	RemoveHeaders []string
+	// BaseUrl overrides the client's base URL for the request
+	BaseUrl string
 }
 
 type HttpResponse struct {
 	Status  int
 	Body    []byte
 	Headers map[string][]string
+	// TLS describes the TLS connection, or is nil for plain HTTP
+	TLS *TLSInfo
+	// URL is the final URL of the request, after following redirects
+	URL string
+	// Redirects is the chain of redirects that were followed, in order
+	Redirects []Redirect
+	// Protocol is the protocol of the response, e.g. HTTP/1.1 or HTTP/2.0
+	Protocol string
 }
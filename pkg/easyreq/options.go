@@ -1,6 +1,7 @@
 package easyreq
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/mrfoh/httpprobe/internal/logging"
@@ -10,17 +11,38 @@ type HttpClientOptions struct {
 	Logger logging.Logger
 	// BaseUrl is the base URL to use for all requests
 	BaseUrl string
-	// Timeout is the timeout in milliseconds for the request
+	// Timeout is the timeout in milliseconds for each request. Zero disables the timeout.
 	Timeout int
-	// Headers is a map of headers to include in every request. This will be merged with any other headers passed in
+	// Headers is a map of headers to include in every request. This will be merged with any other headers passed in.
+	// A []string value is sent as repeated headers, in order.
 	Headers map[string]interface{}
+	// UserAgent is the User-Agent of every request, unless a request sets its own. An empty
+	// UserAgent sends no User-Agent.
+	UserAgent string
+	// Signer signs every request that does not have its own signer
+	Signer RequestSigner
+	// TLS configures certificate verification and client certificates
+	TLS *TLSOptions
+	// Jar stores cookies from responses and sends them with later requests. Cookies are
+	// not kept when it is nil.
+	Jar http.CookieJar
+	// Proxy configures the proxy requests are sent through. When it is nil, the proxy is
+	// taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy *ProxyOptions
+	// Resolve maps "host:port", or "host" for any port, to the IP address to connect to
+	// instead of looking the host up, like curl's --resolve
+	Resolve map[string]string
+	// HTTPVersion is the HTTP version to use: HTTPVersion11, HTTPVersion2 or HTTPVersionH2C.
+	// By default HTTP/2 is used when the server negotiates it over TLS.
+	HTTPVersion string
 }
 
 func NewOptions() *HttpClientOptions {
 	// Default options
 	return &HttpClientOptions{
-		Timeout: 10000,
-		Headers: make(map[string]interface{}),
+		Timeout:   10000,
+		Headers:   make(map[string]interface{}),
+		UserAgent: DefaultUserAgent,
 	}
 }
 
@@ -44,6 +66,66 @@ func (o *HttpClientOptions) WithHeaders(headers map[string]interface{}) *HttpCli
 	return o
 }
 
+func (o *HttpClientOptions) WithSigner(signer RequestSigner) *HttpClientOptions {
+	o.Signer = signer
+	return o
+}
+
+func (o *HttpClientOptions) WithCookieJar(jar http.CookieJar) *HttpClientOptions {
+	o.Jar = jar
+	return o
+}
+
+func (o *HttpClientOptions) WithTLS(tls *TLSOptions) *HttpClientOptions {
+	o.TLS = tls
+	return o
+}
+
+func (o *HttpClientOptions) WithProxy(proxy *ProxyOptions) *HttpClientOptions {
+	o.Proxy = proxy
+	return o
+}
+
+func (o *HttpClientOptions) WithResolve(resolve map[string]string) *HttpClientOptions {
+	o.Resolve = resolve
+	return o
+}
+
+func (o *HttpClientOptions) WithHTTPVersion(version string) *HttpClientOptions {
+	o.HTTPVersion = version
+	return o
+}
+
+func (o *HttpClientOptions) WithUserAgent(userAgent string) *HttpClientOptions {
+	o.UserAgent = userAgent
+	return o
+}
+
+// Clone returns a copy of the options that can be changed without affecting the original
+func (o *HttpClientOptions) Clone() *HttpClientOptions {
+	clone := *o
+	clone.Headers = make(map[string]interface{}, len(o.Headers))
+	for k, v := range o.Headers {
+		clone.Headers[k] = v
+	}
+	if o.TLS != nil {
+		tlsOptions := *o.TLS
+		clone.TLS = &tlsOptions
+	}
+	if o.Proxy != nil {
+		proxyOptions := *o.Proxy
+		proxyOptions.NoProxy = append([]string(nil), o.Proxy.NoProxy...)
+		clone.Proxy = &proxyOptions
+	}
+	if o.Resolve != nil {
+		clone.Resolve = make(map[string]string, len(o.Resolve))
+		for k, v := range o.Resolve {
+			clone.Resolve[k] = v
+		}
+	}
+	return &clone
+}
+
 func (o *HttpClientOptions) GetTimeout() time.Duration {
 	return time.Duration(o.Timeout) * time.Millisecond
 }
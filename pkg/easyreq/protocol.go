@@ -0,0 +1,55 @@
+package easyreq
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// HTTP versions for HttpClientOptions.HTTPVersion
+const (
+	// HTTPVersion11 only uses HTTP/1.1
+	HTTPVersion11 = "1.1"
+	// HTTPVersion2 uses HTTP/2 when the server negotiates it over TLS, and HTTP/1.1 otherwise
+	HTTPVersion2 = "2"
+	// HTTPVersionH2C uses HTTP/2 without TLS for http:// URLs, with prior knowledge that the
+	// server supports it, and HTTP/2 over TLS for https:// URLs
+	HTTPVersionH2C = "h2c"
+)
+
+// ValidateHTTPVersion checks that version is a supported HTTP version, or empty for the default
+func ValidateHTTPVersion(version string) error {
+	switch version {
+	case "", HTTPVersion11, HTTPVersion2, HTTPVersionH2C:
+		return nil
+	default:
+		return fmt.Errorf("unsupported http_version %q, expected 1.1, 2 or h2c", version)
+	}
+}
+
+// configureHTTPVersion configures the protocols transport uses for version
+func configureHTTPVersion(transport *http.Transport, version string) error {
+	if err := ValidateHTTPVersion(version); err != nil {
+		return err
+	}
+
+	switch version {
+	case HTTPVersion11:
+		// An empty, non-nil TLSNextProto disables HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		// The TLS config cloned from the default transport offers h2 once the default
+		// transport has made an HTTPS request, so only offer HTTP/1.1 to the server
+		tlsConfig := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+		tlsConfig.NextProtos = []string{"http/1.1"}
+		transport.TLSClientConfig = tlsConfig
+	case HTTPVersion2:
+		transport.ForceAttemptHTTP2 = true
+	case HTTPVersionH2C:
+		return configureH2C(transport)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build go1.24
+
+package easyreq
+
+import (
+	"net/http"
+)
+
+// configureH2C enables HTTP/2 without TLS, and HTTP/2 over TLS for https:// URLs
+func configureH2C(transport *http.Transport) error {
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetHTTP2(true)
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build go1.24
+
+package easyreq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPVersionH2C(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	resp, err := New(NewOptions().WithHTTPVersion(HTTPVersionH2C)).Get(context.Background(), server.URL, RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Protocol != "HTTP/2.0" || string(resp.Body) != "HTTP/2.0" {
+		t.Errorf("expected cleartext HTTP/2, got %s with %s at the server", resp.Protocol, resp.Body)
+	}
+
+	// Without h2c the same server is used over HTTP/1.1
+	resp, err = New(NewOptions()).Get(context.Background(), server.URL, RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Protocol != "HTTP/1.1" {
+		t.Errorf("expected HTTP/1.1 by default, got %s", resp.Protocol)
+	}
+}
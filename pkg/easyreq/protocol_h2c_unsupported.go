@@ -0,0 +1,13 @@
+//go:build !go1.24
+
+package easyreq
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// configureH2C fails, since HTTP/2 without TLS needs the transport protocols added in Go 1.24
+func configureH2C(transport *http.Transport) error {
+	return fmt.Errorf("http_version h2c requires httpprobe to be built with Go 1.24 or later")
+}
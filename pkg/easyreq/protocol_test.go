@@ -0,0 +1,63 @@
+package easyreq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{version: "", expected: "HTTP/2.0"},
+		{version: HTTPVersion2, expected: "HTTP/2.0"},
+		{version: HTTPVersion11, expected: "HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run("version "+tt.version, func(t *testing.T) {
+			client := New(NewOptions().WithTLS(&TLSOptions{InsecureSkipVerify: true}).WithHTTPVersion(tt.version))
+			resp, err := client.Get(context.Background(), server.URL, RequestParams{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Protocol != tt.expected || string(resp.Body) != tt.expected {
+				t.Errorf("expected %s, got %s with %s at the server", tt.expected, resp.Protocol, resp.Body)
+			}
+		})
+	}
+
+	// The default transport offers h2 once it has made an HTTPS request, which HTTP/1.1
+	// clients cloned from it must not offer as well
+	http.Get(server.URL)
+	transport, err := newTransport(NewOptions().WithHTTPVersion(HTTPVersion11))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+	client := New(NewOptions())
+	client.(*HttpClientImpl).Client.Transport = transport
+	resp, err := client.Get(context.Background(), server.URL, RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error after a default transport request: %v", err)
+	}
+	if resp.Protocol != "HTTP/1.1" {
+		t.Errorf("expected HTTP/1.1 after a default transport request, got %s", resp.Protocol)
+	}
+
+	client = New(NewOptions().WithHTTPVersion("3"))
+	if _, err := client.Get(context.Background(), server.URL, RequestParams{}); err == nil || !strings.Contains(err.Error(), "unsupported http_version") {
+		t.Errorf("expected an unsupported version error, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package easyreq
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProxyOptions configures the proxy requests are sent through
+type ProxyOptions struct {
+	// URL is the proxy URL, e.g. http://proxy.internal:3128 or socks5://localhost:1080. The
+	// http, https, socks5 and socks5h schemes are supported. An empty URL connects directly,
+	// ignoring the proxy environment variables.
+	URL string
+	// NoProxy lists hosts that are connected to directly: host names, which also match their
+	// subdomains, domains with a leading dot, which only match subdomains, IP addresses,
+	// CIDR ranges and "*" for all hosts. An entry can end with a port to only match it.
+	NoProxy []string
+}
+
+// proxyFunc returns the function the transport uses to pick the proxy for a request
+func (p *ProxyOptions) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if p.URL == "" {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https, socks5 or socks5h", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", p.URL)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if p.bypass(req.URL) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// bypass reports whether requests to target are sent directly instead of through the proxy
+func (p *ProxyOptions) bypass(target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+
+	for _, entry := range p.NoProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		// Entries with a port only match requests to that port
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = entryHost
+		}
+		entry = strings.Trim(entry, "[]")
+
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseResolve parses curl style host:port:address entries, e.g. "api.example.com:443:10.0.0.5",
+// into a map of host:port to address for HttpClientOptions.Resolve
+func ParseResolve(entries []string) (map[string]string, error) {
+	resolve := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid resolve entry %q, expected host:port:address", entry)
+		}
+		resolve[net.JoinHostPort(parts[0], parts[1])] = strings.Trim(parts[2], "[]")
+	}
+
+	if err := validateResolve(resolve); err != nil {
+		return nil, err
+	}
+	return resolve, nil
+}
+
+// validateResolve checks that resolve maps host:port or host keys to IP addresses
+func validateResolve(resolve map[string]string) error {
+	for key, address := range resolve {
+		if key == "" {
+			return fmt.Errorf("invalid resolve entry: missing host")
+		}
+		if net.ParseIP(address) == nil {
+			return fmt.Errorf("invalid resolve address for %s: %q is not an IP address", key, address)
+		}
+	}
+	return nil
+}
+
+// resolveDialer returns a dial function that connects to the address in resolve for a
+// host:port, or a host on any port, instead of looking the host up
+func resolveDialer(resolve map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	// Same settings as http.DefaultTransport's dialer
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	overrides := make(map[string]string, len(resolve))
+	for key, address := range resolve {
+		overrides[strings.ToLower(key)] = address
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			address, ok := overrides[strings.ToLower(addr)]
+			if !ok {
+				address, ok = overrides[strings.ToLower(host)]
+			}
+			if ok {
+				addr = net.JoinHostPort(address, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
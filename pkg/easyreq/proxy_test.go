@@ -0,0 +1,119 @@
+package easyreq
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	// The proxy answers itself, so requests to hosts that do not exist succeed through it
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	client := New(NewOptions().WithProxy(&ProxyOptions{URL: proxy.URL, NoProxy: []string{"internal.test"}}))
+
+	resp, err := client.Get(context.Background(), "http://api.example.test/users", RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != http.StatusNoContent || len(proxied) != 1 || proxied[0] != "http://api.example.test/users" {
+		t.Errorf("expected the request to go through the proxy, got %d %v", resp.Status, proxied)
+	}
+
+	// Hosts in no_proxy are connected to directly and fail to resolve
+	if _, err := client.Get(context.Background(), "http://db.internal.test/", RequestParams{}); err == nil {
+		t.Errorf("expected a direct connection to an unknown host to fail")
+	}
+	if len(proxied) != 1 {
+		t.Errorf("expected no_proxy hosts to bypass the proxy, got %v", proxied)
+	}
+
+	// Unsupported proxies fail every request
+	client = New(NewOptions().WithProxy(&ProxyOptions{URL: "ftp://proxy.test:21"}))
+	if _, err := client.Get(context.Background(), "http://api.example.test/", RequestParams{}); err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Errorf("expected an unsupported proxy scheme error, got %v", err)
+	}
+}
+
+func TestProxyBypass(t *testing.T) {
+	proxy := &ProxyOptions{
+		URL:     "http://proxy.test:3128",
+		NoProxy: []string{"example.com", ".internal", "10.0.0.0/8", "127.0.0.1", "api.test:8443"},
+	}
+
+	tests := []struct {
+		url    string
+		bypass bool
+	}{
+		{"https://example.com/", true},
+		{"https://api.example.com/", true},
+		{"https://notexample.com/", false},
+		{"http://db.internal/", true},
+		{"http://internal/", false},
+		{"http://10.1.2.3:8080/", true},
+		{"http://127.0.0.1/", true},
+		{"http://192.168.1.1/", false},
+		{"https://api.test:8443/", true},
+		{"https://api.test/", false},
+	}
+
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.url)
+		if bypass := proxy.bypass(target); bypass != tt.bypass {
+			t.Errorf("bypass(%s) = %v, expected %v", tt.url, bypass, tt.bypass)
+		}
+	}
+
+	if !(&ProxyOptions{NoProxy: []string{"*"}}).bypass(&url.URL{Scheme: "https", Host: "anything.test"}) {
+		t.Errorf("expected * to bypass the proxy for every host")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	resolve, err := ParseResolve([]string{"api.example.test:" + port + ":127.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := New(NewOptions().WithResolve(resolve))
+	resp, err := client.Get(context.Background(), "http://api.example.test:"+port+"/health", RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != http.StatusOK || host != "api.example.test:"+port {
+		t.Errorf("expected the request for api.example.test to reach the server, got %d with host %q", resp.Status, host)
+	}
+}
+
+func TestParseResolve(t *testing.T) {
+	resolve, err := ParseResolve([]string{"api.example.com:443:10.0.0.5", "v6.example.com:443:[::1]"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolve["api.example.com:443"] != "10.0.0.5" || resolve["v6.example.com:443"] != "::1" {
+		t.Errorf("unexpected resolve entries: %v", resolve)
+	}
+
+	for _, entry := range []string{"api.example.com:10.0.0.5", "api.example.com:443:not-an-ip", ":443:10.0.0.5"} {
+		if _, err := ParseResolve([]string{entry}); err == nil {
+			t.Errorf("expected an error for %q", entry)
+		}
+	}
+}
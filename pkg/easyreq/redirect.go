@@ -0,0 +1,53 @@
+package easyreq
+
+import (
+	"net/http"
+)
+
+// DefaultMaxRedirects is the number of redirects followed when a request does not set a limit
+const DefaultMaxRedirects = 10
+
+// Redirect is a hop in the redirect chain of a response
+type Redirect struct {
+	// Status is the status code of the redirect response
+	Status int
+	// URL is the URL that responded with the redirect
+	URL string
+	// Location is the Location header of the redirect, as sent by the server
+	Location string
+	// Cookies are the cookies set by the redirect response
+	Cookies []*http.Cookie
+}
+
+// redirectPolicy returns a CheckRedirect function that follows up to maxRedirects
+// redirects and records each hop in chain. A nil maxRedirects follows up to
+// DefaultMaxRedirects. When the server redirects more often, the last redirect response
+// is returned, so it can be checked along with the chain that was followed.
+func redirectPolicy(maxRedirects *int, chain *[]Redirect) func(req *http.Request, via []*http.Request) error {
+	limit := DefaultMaxRedirects
+	if maxRedirects != nil {
+		limit = *maxRedirects
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		// Without following, or after following the maximum, the redirect response itself
+		// is returned
+		if len(via) > limit {
+			return http.ErrUseLastResponse
+		}
+
+		hop := Redirect{
+			URL:      via[len(via)-1].URL.String(),
+			Location: req.URL.String(),
+		}
+		if req.Response != nil {
+			hop.Status = req.Response.StatusCode
+			hop.Cookies = req.Response.Cookies()
+			if location := req.Response.Header.Get("Location"); location != "" {
+				hop.Location = location
+			}
+		}
+		*chain = append(*chain, hop)
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package easyreq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.SetCookie(w, &http.Cookie{Name: "step", Value: "old"})
+			http.Redirect(w, r, "/mid", http.StatusMovedPermanently)
+		case "/mid":
+			http.Redirect(w, r, "/new?page=2", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := New(NewOptions())
+	intPtr := func(n int) *int { return &n }
+
+	resp, err := client.Get(context.Background(), server.URL+"/old", RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != http.StatusOK || resp.URL != server.URL+"/new?page=2" {
+		t.Errorf("expected to end at /new?page=2 with 200, got %d at %s", resp.Status, resp.URL)
+	}
+	expected := []Redirect{
+		{Status: http.StatusMovedPermanently, URL: server.URL + "/old", Location: "/mid"},
+		{Status: http.StatusFound, URL: server.URL + "/mid", Location: "/new?page=2"},
+	}
+	if len(resp.Redirects) != len(expected) {
+		t.Fatalf("expected %d redirects, got %v", len(expected), resp.Redirects)
+	}
+	for i, redirect := range resp.Redirects {
+		if redirect.Status != expected[i].Status || redirect.URL != expected[i].URL || redirect.Location != expected[i].Location {
+			t.Errorf("redirect %d: expected %+v, got %+v", i, expected[i], redirect)
+		}
+	}
+	if cookies := resp.Redirects[0].Cookies; len(cookies) != 1 || cookies[0].Name != "step" || cookies[0].Value != "old" {
+		t.Errorf("expected the cookie set by the first redirect, got %v", cookies)
+	}
+
+	// Not following redirects returns the redirect response itself
+	resp, err = client.Get(context.Background(), server.URL+"/old", RequestParams{MaxRedirects: intPtr(0)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != http.StatusMovedPermanently || http.Header(resp.Headers).Get("Location") != "/mid" || len(resp.Redirects) != 0 {
+		t.Errorf("expected the 301 response without redirects, got %d %v", resp.Status, resp.Redirects)
+	}
+
+	// Exceeding the maximum returns the last redirect response with the chain followed so far
+	resp, err = client.Get(context.Background(), server.URL+"/old", RequestParams{MaxRedirects: intPtr(1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != http.StatusFound || http.Header(resp.Headers).Get("Location") != "/new?page=2" {
+		t.Errorf("expected the 302 response from /mid, got %d %v", resp.Status, resp.Headers)
+	}
+	if resp.URL != server.URL+"/mid" || len(resp.Redirects) != 1 || resp.Redirects[0].URL != server.URL+"/old" {
+		t.Errorf("expected one redirect followed to /mid, got %s %+v", resp.URL, resp.Redirects)
+	}
+}
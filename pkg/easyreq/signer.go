@@ -0,0 +1,326 @@
+package easyreq
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestSigner signs a request just before it is sent. Signers see the final request,
+// including all headers and the encoded body, so signatures cover the bytes on the wire.
+type RequestSigner interface {
+	// Sign adds a signature to req. body is the encoded request body, or nil.
+	Sign(req *http.Request, body []byte) error
+}
+
+// RequestSignerFunc adapts an ordinary function to a RequestSigner
+type RequestSignerFunc func(req *http.Request, body []byte) error
+
+// Sign calls f(req, body)
+func (f RequestSignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// AWSSigV4Signer signs requests with AWS Signature Version 4, e.g. for API Gateway
+// endpoints that use IAM authorization
+type AWSSigV4Signer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is the token for temporary credentials, if any
+	SessionToken string
+	// Region is the AWS region, e.g. us-east-1
+	Region string
+	// Service is the signing name of the service, e.g. execute-api
+	Service string
+	// Now returns the signing time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Sign adds the X-Amz-Date and Authorization headers to req
+func (s *AWSSigV4Signer) Sign(req *http.Request, body []byte) error {
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return fmt.Errorf("aws_sigv4 signing requires an access key ID and secret access key")
+	}
+	if s.Region == "" || s.Service == "" {
+		return fmt.Errorf("aws_sigv4 signing requires a region and service")
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	if s.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	// Sign the host and every header that is not added or changed by the transport
+	headerNames := []string{"host"}
+	canonicalHeaders := map[string]string{"host": req.Host}
+	if canonicalHeaders["host"] == "" {
+		canonicalHeaders["host"] = req.URL.Host
+	}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "authorization" || lower == "user-agent" || lower == "content-length" {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		canonicalHeaders[lower] = strings.Join(trimmed, ",")
+		headerNames = append(headerNames, lower)
+	}
+	sort.Strings(headerNames)
+
+	var headerLines strings.Builder
+	for _, name := range headerNames {
+		headerLines.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalPath(req),
+		canonicalQuery(req),
+		headerLines.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.Region, s.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSum(sha256.New, []byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSum(sha256.New, key, s.Region)
+	key = hmacSum(sha256.New, key, s.Service)
+	key = hmacSum(sha256.New, key, "aws4_request")
+	signature := hex.EncodeToString(hmacSum(sha256.New, key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// canonicalPath returns the URI-encoded request path. Every service except S3 encodes the
+// already escaped path a second time.
+func (s *AWSSigV4Signer) canonicalPath(req *http.Request) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if s.Service == "s3" {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the query string with encoded keys and values sorted by key and value
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte except unreserved characters, as required by SigV4
+func awsURIEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// DefaultHMACTemplate is the string to sign used by HMACSigner when no template is set
+const DefaultHMACTemplate = "{method}\n{path}\n{date}\n{body_sha256}"
+
+// HMACSigner signs requests with an HMAC over a string built from the request. The string
+// to sign is built from Template, which can contain these placeholders:
+//
+//	{method}       request method
+//	{path}         escaped request path
+//	{query}        raw query string
+//	{host}         request host
+//	{date}         value of the date header
+//	{body_sha256}  hex SHA-256 of the body
+//	{body_md5}     hex MD5 of the body
+//	{header:Name}  value of a request header
+type HMACSigner struct {
+	// Key is the shared secret
+	Key string
+	// Algorithm is the hash used for the HMAC: sha256 (default), sha1 or sha512
+	Algorithm string
+	// Header is the header the signature is sent in. Defaults to X-Signature.
+	Header string
+	// Prefix is prepended to the signature in the header, e.g. "HMAC "
+	Prefix string
+	// Template is the string to sign. Defaults to DefaultHMACTemplate.
+	Template string
+	// DateHeader is the header holding the request date. It is set to the current time unless
+	// the request already has it. Defaults to X-Date.
+	DateHeader string
+	// DateFormat is the Go time layout of the date header. Defaults to http.TimeFormat.
+	DateFormat string
+	// Encoding of the signature: hex (default) or base64
+	Encoding string
+	// Now returns the signing time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Sign adds the date and signature headers to req
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	var newHash func() hash.Hash
+	switch strings.ToLower(s.Algorithm) {
+	case "", "sha256":
+		newHash = sha256.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return fmt.Errorf("unsupported hmac algorithm: %s", s.Algorithm)
+	}
+
+	dateHeader := valueOrDefault(s.DateHeader, "X-Date")
+	date := req.Header.Get(dateHeader)
+	if date == "" {
+		now := time.Now
+		if s.Now != nil {
+			now = s.Now
+		}
+		date = now().UTC().Format(valueOrDefault(s.DateFormat, http.TimeFormat))
+		req.Header.Set(dateHeader, date)
+	}
+
+	stringToSign, err := s.stringToSign(req, body, date)
+	if err != nil {
+		return err
+	}
+
+	sum := hmacSum(newHash, []byte(s.Key), stringToSign)
+
+	var signature string
+	switch strings.ToLower(s.Encoding) {
+	case "", "hex":
+		signature = hex.EncodeToString(sum)
+	case "base64":
+		signature = base64.StdEncoding.EncodeToString(sum)
+	default:
+		return fmt.Errorf("unsupported hmac encoding: %s", s.Encoding)
+	}
+
+	req.Header.Set(valueOrDefault(s.Header, "X-Signature"), s.Prefix+signature)
+	return nil
+}
+
+// stringToSign expands the placeholders in the template
+func (s *HMACSigner) stringToSign(req *http.Request, body []byte, date string) (string, error) {
+	template := valueOrDefault(s.Template, DefaultHMACTemplate)
+
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			sb.WriteString(template)
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in hmac template: %s", template[start:])
+		}
+		end += start
+
+		sb.WriteString(template[:start])
+		placeholder := template[start+1 : end]
+		template = template[end+1:]
+
+		switch {
+		case placeholder == "method":
+			sb.WriteString(req.Method)
+		case placeholder == "path":
+			sb.WriteString(req.URL.EscapedPath())
+		case placeholder == "query":
+			sb.WriteString(req.URL.RawQuery)
+		case placeholder == "host":
+			sb.WriteString(req.URL.Host)
+		case placeholder == "date":
+			sb.WriteString(date)
+		case placeholder == "body_sha256":
+			sb.WriteString(sha256Hex(body))
+		case placeholder == "body_md5":
+			sb.WriteString(md5Hex(body))
+		case strings.HasPrefix(placeholder, "header:"):
+			sb.WriteString(req.Header.Get(strings.TrimPrefix(placeholder, "header:")))
+		default:
+			return "", fmt.Errorf("unknown placeholder in hmac template: {%s}", placeholder)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func hmacSum(newHash func() hash.Hash, key []byte, data string) []byte {
+	mac := hmac.New(newHash, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
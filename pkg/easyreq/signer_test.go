@@ -0,0 +1,113 @@
+package easyreq
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from the AWS Signature Version 4 test suite
+func TestAWSSigV4Signer(t *testing.T) {
+	signer := &AWSSigV4Signer{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+		Now: func() time.Time {
+			return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+		},
+	}
+
+	tests := []struct {
+		name      string
+		url       string
+		signature string
+	}{
+		{
+			name:      "get-vanilla",
+			url:       "https://example.amazonaws.com/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			url:       "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := signer.Sign(req, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=" + tt.signature
+			if got := req.Header.Get("Authorization"); got != expected {
+				t.Errorf("Authorization = %q, want %q", got, expected)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+		})
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	now := func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/orders?id=1", strings.NewReader(`{"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := &HMACSigner{Key: "secret", Now: now}
+	if err := signer.Sign(req, []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := req.Header.Get("X-Date"); got != "Tue, 02 Jan 2024 03:04:05 GMT" {
+		t.Errorf("X-Date = %q", got)
+	}
+
+	stringToSign := "POST\n/v1/orders\nTue, 02 Jan 2024 03:04:05 GMT\n" + sha256Hex([]byte(`{"a":1}`))
+	expected := hexHMAC("secret", stringToSign)
+	if got := req.Header.Get("X-Signature"); got != expected {
+		t.Errorf("X-Signature = %q, want %q", got, expected)
+	}
+
+	custom := &HMACSigner{
+		Key:      "secret",
+		Header:   "Authorization",
+		Prefix:   "HMAC ",
+		Template: "{method} {path}?{query} {header:X-Client}",
+		Encoding: "base64",
+		Now:      now,
+	}
+	req.Header.Set("X-Client", "abc")
+	if err := custom.Sign(req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); !strings.HasPrefix(got, "HMAC ") || len(got) != len("HMAC ")+44 {
+		t.Errorf("unexpected Authorization header %q", got)
+	}
+
+	invalid := &HMACSigner{Key: "secret", Template: "{unknown}"}
+	if err := invalid.Sign(req, nil); err == nil {
+		t.Error("expected error for unknown placeholder")
+	}
+}
+
+func hexHMAC(key string, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,68 @@
+package easyreq
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// unixScheme is the scheme of unix socket targets, e.g. unix:///var/run/docker.sock:/v1.43/info
+const unixScheme = "unix://"
+
+// parseUnixTarget splits a unix:///path/to.sock:/http/path target into the socket path and
+// the HTTP path. The HTTP path defaults to "/".
+func parseUnixTarget(target string) (socket string, path string, ok bool) {
+	if !strings.HasPrefix(target, unixScheme) {
+		return "", "", false
+	}
+
+	rest := strings.TrimPrefix(target, unixScheme)
+	if idx := strings.Index(rest, ":/"); idx >= 0 {
+		return rest[:idx], rest[idx+1:], true
+	}
+	return rest, "/", true
+}
+
+// socketUrl returns the URL of a request sent over a unix socket. URLs without a host, such
+// as a path, are sent to http://localhost since the host is not used to connect.
+func socketUrl(requestUrl string) string {
+	parsed, err := url.Parse(requestUrl)
+	if err != nil || parsed.Host != "" {
+		return requestUrl
+	}
+
+	parsed.Scheme = "http"
+	parsed.Host = "localhost"
+	return parsed.String()
+}
+
+// socketTransport returns the transport for requests to the unix socket at socket. Each
+// socket gets its own copy of the client's transport, so connections to different sockets
+// are never pooled together. Requests over a socket never use a proxy.
+func (c *HttpClientImpl) socketTransport(socket string) http.RoundTripper {
+	c.socketsMu.Lock()
+	defer c.socketsMu.Unlock()
+
+	if transport, ok := c.sockets[socket]; ok {
+		return transport
+	}
+
+	base, ok := c.Client.Transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+
+	if c.sockets == nil {
+		c.sockets = make(map[string]*http.Transport)
+	}
+	c.sockets[socket] = transport
+	return transport
+}
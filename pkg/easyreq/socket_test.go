@@ -0,0 +1,69 @@
+package easyreq
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+// serveSocket serves handler on a unix socket in a temporary directory and returns its path
+func serveSocket(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %v", err)
+	}
+	server := &http.Server{Handler: handler}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return socket
+}
+
+func TestUnixSocket(t *testing.T) {
+	first := serveSocket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first " + r.URL.RequestURI()))
+	})
+	second := serveSocket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("second " + r.URL.RequestURI()))
+	})
+
+	client := New(NewOptions())
+
+	tests := []struct {
+		name     string
+		url      string
+		params   RequestParams
+		expected string
+	}{
+		{name: "unix target", url: "unix://" + first + ":/v1/info", expected: "first /v1/info"},
+		{name: "unix target with query", url: "unix://" + second + ":/containers", params: RequestParams{Query: map[string]interface{}{"all": 1}}, expected: "second /containers?all=1"},
+		{name: "unix target without path", url: "unix://" + first, expected: "first /"},
+		{name: "socket option", url: "/_ping", params: RequestParams{Socket: second}, expected: "second /_ping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(context.Background(), tt.url, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(resp.Body) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, resp.Body)
+			}
+		})
+	}
+
+	// The base URL does not apply to unix targets
+	resp, err := New(NewOptions().WithBaseUrl("https://api.example.com")).Get(context.Background(), "unix://"+first+":/v1/info", RequestParams{})
+	if err != nil || string(resp.Body) != "first /v1/info" {
+		t.Errorf("expected the unix target to ignore the base URL, got %v", err)
+	}
+
+	if _, err := client.Get(context.Background(), "unix://"+filepath.Join(t.TempDir(), "missing.sock")+":/", RequestParams{}); err == nil {
+		t.Errorf("expected an error for a missing socket")
+	}
+}
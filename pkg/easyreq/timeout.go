@@ -0,0 +1,99 @@
+package easyreq
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Phases of a request, reported when a request times out
+const (
+	PhaseConnecting   = "connecting"
+	PhaseResolvingDNS = "resolving DNS"
+	PhaseTLSHandshake = "TLS handshake"
+	PhaseSending      = "sending request"
+	PhaseWaiting      = "waiting for response"
+	PhaseReadingBody  = "reading response body"
+)
+
+// TimeoutError is returned when a request does not complete within its timeout
+type TimeoutError struct {
+	// Timeout is the timeout that was exceeded
+	Timeout time.Duration
+	// Elapsed is the time from the start of the request until it was abandoned
+	Elapsed time.Duration
+	// Phase is the phase the request was in when it timed out, e.g. "waiting for response"
+	Phase string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %s while %s (timeout %s)",
+		e.Elapsed.Round(time.Millisecond), e.Phase, e.Timeout)
+}
+
+// Unwrap allows errors.Is(err, context.DeadlineExceeded)
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// phaseTracker records the current phase of a request from httptrace callbacks, which
+// may be called from other goroutines
+type phaseTracker struct {
+	mu    sync.Mutex
+	phase string
+	start time.Time
+}
+
+func newPhaseTracker() *phaseTracker {
+	return &phaseTracker{
+		phase: PhaseConnecting,
+		start: time.Now(),
+	}
+}
+
+func (t *phaseTracker) set(phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = phase
+}
+
+func (t *phaseTracker) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phase
+}
+
+// clientTrace returns the httptrace hooks that update the tracker
+func (t *phaseTracker) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn:              func(string) { t.set(PhaseConnecting) },
+		DNSStart:             func(httptrace.DNSStartInfo) { t.set(PhaseResolvingDNS) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.set(PhaseConnecting) },
+		TLSHandshakeStart:    func() { t.set(PhaseTLSHandshake) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.set(PhaseSending) },
+		GotConn:              func(httptrace.GotConnInfo) { t.set(PhaseSending) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(PhaseWaiting) },
+		GotFirstResponseByte: func() { t.set(PhaseReadingBody) },
+	}
+}
+
+// requestError converts an error from sending a request. A request whose own timeout
+// expired returns a TimeoutError; a request whose parent context was cancelled returns an
+// error wrapping the cancellation cause.
+func (t *phaseTracker) requestError(ctx, requestCtx context.Context, timeout time.Duration, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("request cancelled while %s: %w", t.current(), context.Cause(ctx))
+	}
+	if errors.Is(requestCtx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{
+			Timeout: timeout,
+			Elapsed: time.Since(t.start),
+			Phase:   t.current(),
+		}
+	}
+	return fmt.Errorf("error making request: %v", err)
+}
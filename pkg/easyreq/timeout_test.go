@@ -0,0 +1,70 @@
+package easyreq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-body" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	tests := []struct {
+		name  string
+		path  string
+		phase string
+	}{
+		{name: "slow response", path: "/slow", phase: PhaseWaiting},
+		{name: "slow body", path: "/slow-body", phase: PhaseReadingBody},
+	}
+
+	client := New(NewOptions().WithTimeout(10000))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Get(context.Background(), server.URL+tt.path, RequestParams{Timeout: 50 * time.Millisecond})
+
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("expected a timeout error, got %v", err)
+			}
+			if timeoutErr.Phase != tt.phase {
+				t.Errorf("expected phase %q, got %q", tt.phase, timeoutErr.Phase)
+			}
+			if timeoutErr.Timeout != 50*time.Millisecond || timeoutErr.Elapsed < timeoutErr.Timeout {
+				t.Errorf("unexpected timeout %s and elapsed %s", timeoutErr.Timeout, timeoutErr.Elapsed)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the error to match context.DeadlineExceeded")
+			}
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := client.Get(ctx, server.URL+"/slow", RequestParams{})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected a cancellation error, got %v", err)
+		}
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) {
+			t.Errorf("expected a cancelled request not to be reported as a timeout")
+		}
+	})
+}
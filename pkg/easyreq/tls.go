@@ -0,0 +1,154 @@
+package easyreq
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSOptions configures TLS for the client's connections
+type TLSOptions struct {
+	// CAFile is a PEM bundle of certificate authorities to trust in addition to the system roots
+	CAFile string
+	// CertFile is the PEM client certificate for mutual TLS
+	CertFile string
+	// KeyFile is the PEM private key of the client certificate
+	KeyFile string
+	// ServerName overrides the host name used to verify the server certificate and for SNI
+	ServerName string
+	// MinVersion is the minimum TLS version: 1.0, 1.1, 1.2 or 1.3
+	MinVersion string
+	// InsecureSkipVerify disables verification of the server certificate
+	InsecureSkipVerify bool
+	// Pins are base64 SHA-256 hashes of the public key of a certificate in the server's
+	// chain, optionally prefixed with "sha256/". When set, connections to servers that do not
+	// present a pinned key fail, even if verification is disabled.
+	Pins []string
+}
+
+// tlsVersions maps TLS version names to their crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config builds the tls.Config for the options
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.MinVersion != "" {
+		version, ok := tlsVersions[strings.TrimPrefix(o.MinVersion, "TLS")]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version: %s", o.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", o.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and key file are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(o.Pins) > 0 {
+		pins := make(map[string]bool, len(o.Pins))
+		for _, pin := range o.Pins {
+			pins[strings.TrimPrefix(pin, "sha256/")] = true
+		}
+
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			for _, cert := range state.PeerCertificates {
+				if pins[PublicKeyPin(cert)] {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate does not match any pinned public key")
+		}
+	}
+
+	return config, nil
+}
+
+// PublicKeyPin returns the base64 SHA-256 hash of the certificate's subject public key info,
+// the value used for certificate pinning
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// TLSInfo describes the TLS connection a response was received on
+type TLSInfo struct {
+	// Version is the negotiated TLS version, e.g. "1.3"
+	Version string
+	// ServerName is the server name sent in the handshake
+	ServerName string
+	// PeerCertificates is the server's certificate chain, leaf first
+	PeerCertificates []CertificateInfo
+}
+
+// CertificateInfo describes a certificate presented by the server
+type CertificateInfo struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// newTLSInfo returns the TLSInfo for a connection state, or nil for plain HTTP
+func newTLSInfo(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		ServerName: state.ServerName,
+	}
+	for name, version := range tlsVersions {
+		if version == state.Version {
+			info.Version = name
+		}
+	}
+	for _, cert := range state.PeerCertificates {
+		info.PeerCertificates = append(info.PeerCertificates, CertificateInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+		})
+	}
+
+	return info
+}
@@ -0,0 +1,163 @@
+package easyreq
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writePEM writes a PEM block to a file in dir and returns its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	pin := PublicKeyPin(server.Certificate())
+
+	tests := []struct {
+		name        string
+		tls         *TLSOptions
+		shouldError bool
+	}{
+		{
+			name:        "unknown authority",
+			tls:         &TLSOptions{},
+			shouldError: true,
+		},
+		{
+			name: "custom CA",
+			tls:  &TLSOptions{CAFile: caFile},
+		},
+		{
+			name: "insecure skip verify",
+			tls:  &TLSOptions{InsecureSkipVerify: true},
+		},
+		{
+			name: "matching pin",
+			tls:  &TLSOptions{CAFile: caFile, Pins: []string{"sha256/" + pin}},
+		},
+		{
+			name:        "mismatched pin",
+			tls:         &TLSOptions{InsecureSkipVerify: true, Pins: []string{"sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}},
+			shouldError: true,
+		},
+		{
+			name:        "server name mismatch",
+			tls:         &TLSOptions{CAFile: caFile, ServerName: "api.example.org"},
+			shouldError: true,
+		},
+		{
+			name:        "missing CA file",
+			tls:         &TLSOptions{CAFile: filepath.Join(dir, "missing.pem")},
+			shouldError: true,
+		},
+		{
+			name:        "unsupported min version",
+			tls:         &TLSOptions{InsecureSkipVerify: true, MinVersion: "1.4"},
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(NewOptions().WithTLS(tt.tls))
+
+			resp, err := client.Get(context.Background(), server.URL, RequestParams{})
+			if tt.shouldError {
+				if err == nil {
+					t.Fatalf("expected an error, got status %d", resp.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.TLS == nil {
+				t.Fatalf("expected TLS details on the response")
+			}
+			if resp.TLS.Version == "" || len(resp.TLS.PeerCertificates) == 0 {
+				t.Errorf("expected TLS version and peer certificates, got %+v", resp.TLS)
+			}
+			if !resp.TLS.PeerCertificates[0].NotAfter.Equal(server.Certificate().NotAfter) {
+				t.Errorf("expected certificate expiry %v, got %v", server.Certificate().NotAfter, resp.TLS.PeerCertificates[0].NotAfter)
+			}
+		})
+	}
+}
+
+func TestTLSOptionsClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "httpprobe-client" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "httpprobe-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := writePEM(t, dir, "client.pem", "CERTIFICATE", certDER)
+	keyFile := writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+
+	client := New(NewOptions().WithTLS(&TLSOptions{
+		InsecureSkipVerify: true,
+		MinVersion:         "1.2",
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+	}))
+
+	resp, err := client.Get(context.Background(), server.URL, RequestParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != http.StatusOK {
+		t.Errorf("expected the client certificate to be accepted, got status %d", resp.Status)
+	}
+}